@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/internal/users"
+	"github.com/AlexxIT/go2rtc/pkg/tcp"
 	"github.com/rs/zerolog"
 )
 
@@ -34,6 +36,8 @@ func Init() {
 			UnixListen string `yaml:"unix_listen"`
 			ReadOnly   bool   `yaml:"read_only"`
 
+			ShareSecret string `yaml:"share_secret"`
+
 			AllowPaths []string `yaml:"allow_paths"`
 		} `yaml:"api"`
 	}
@@ -63,13 +67,15 @@ func Init() {
 	HandleFunc("api/restart", restartHandler)
 	HandleFunc("api/log", logHandler)
 
+	initShare(cfg.Mod.ShareSecret)
+
 	Handler = http.DefaultServeMux // 4th
 
 	if cfg.Mod.Origin == "*" {
 		Handler = middlewareCORS(Handler) // 3rd
 	}
 
-	if cfg.Mod.Username != "" {
+	if cfg.Mod.Username != "" || users.Enabled() {
 		Handler = middlewareAuth(cfg.Mod.Username, cfg.Mod.Password, cfg.Mod.LocalAuth, Handler) // 2nd
 	}
 
@@ -113,15 +119,7 @@ func listen(network, address string) {
 }
 
 func tlsListen(network, address, certFile, keyFile string) {
-	var cert tls.Certificate
-	var err error
-	if strings.IndexByte(certFile, '\n') < 0 && strings.IndexByte(keyFile, '\n') < 0 {
-		// check if file path
-		cert, err = tls.LoadX509KeyPair(certFile, keyFile)
-	} else {
-		// if text file content
-		cert, err = tls.X509KeyPair([]byte(certFile), []byte(keyFile))
-	}
+	cert, err := tcp.LoadCertificate(certFile, keyFile)
 	if err != nil {
 		log.Error().Err(err).Caller().Send()
 		return
@@ -224,9 +222,32 @@ func isLoopback(remoteAddr string) bool {
 
 func middlewareAuth(username, password string, localAuth bool, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// ONVIF server checks WS-Security credentials by itself
+		if strings.HasPrefix(r.URL.Path, "/onvif/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// share links work without credentials
+		if r.URL.Query().Has("token") {
+			if user := shareUser(r); user != nil {
+				next.ServeHTTP(w, users.WithUser(r, user))
+				return
+			}
+		}
+
 		if localAuth || !isLoopback(r.RemoteAddr) {
 			user, pass, ok := r.BasicAuth()
-			if !ok || user != username || pass != password {
+			if ok && username != "" && user == username && pass == password {
+				// global credentials have full access
+			} else if u := users.Auth(user, pass); ok && u != nil {
+				if !authorize(r, u) {
+					log.Debug().Msgf("[api] forbidden user=%s url=%s", user, r.URL)
+					http.Error(w, "Forbidden", http.StatusForbidden)
+					return
+				}
+				r = users.WithUser(r, u)
+			} else {
 				w.Header().Set("Www-Authenticate", `Basic realm="go2rtc"`)
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/users"
+)
+
+// shareFormats - formats allowed for share links, the order is important (bit in token)
+var shareFormats = []string{"mp4", "hls", "webrtc", "frame.jpeg"}
+
+// sharePaths - API paths for each format, first path is used for the link,
+// path with slash at the end matches all nested paths
+var sharePaths = map[string][]string{
+	"mp4":        {"/api/stream.mp4"},
+	"hls":        {"/api/stream.m3u8", "/api/hls/playlist.m3u8", "/api/hls/segment.ts", "/api/hls/init.mp4", "/api/hls/segment.m4s"},
+	"webrtc":     {"/api/webrtc", "/api/webrtc/"},
+	"frame.jpeg": {"/api/frame.jpeg"},
+}
+
+const shareTTL = time.Hour
+
+var shareSecret []byte
+
+func initShare(secret string) {
+	if secret != "" {
+		shareSecret = []byte(secret)
+	} else {
+		// share links will be invalid after restart
+		shareSecret = make([]byte, 32)
+		_, _ = rand.Read(shareSecret)
+	}
+
+	HandleFunc("api/share", shareHandler)
+}
+
+type shareResponse struct {
+	Token string            `json:"token"`
+	Exp   int64             `json:"exp"`
+	Links map[string]string `json:"links"`
+}
+
+// shareHandler - mint share link for the stream: POST api/share?src=camera1&formats=mp4,hls&ttl=1h
+func shareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	src := query.Get("src")
+	if src == "" || strings.Contains(src, ":") {
+		http.Error(w, "wrong stream name", http.StatusBadRequest)
+		return
+	}
+
+	var mask byte
+	if s := query.Get("formats"); s != "" {
+		for _, format := range strings.Split(s, ",") {
+			i := slices.Index(shareFormats, format)
+			if i < 0 {
+				http.Error(w, "unknown format: "+format, http.StatusBadRequest)
+				return
+			}
+			mask |= 1 << i
+		}
+	} else {
+		mask = 1<<len(shareFormats) - 1
+	}
+
+	ttl := shareTTL
+	if s := query.Get("ttl"); s != "" {
+		if ttl = parseTTL(s); ttl <= 0 {
+			http.Error(w, "wrong ttl: "+s, http.StatusBadRequest)
+			return
+		}
+	}
+
+	exp := time.Now().Add(ttl).Unix()
+	token := base64.RawURLEncoding.EncodeToString(shareSign(src, mask, exp))
+
+	values := url.Values{
+		"src":   {src},
+		"exp":   {strconv.FormatInt(exp, 10)},
+		"token": {token},
+	}.Encode()
+
+	res := &shareResponse{Token: token, Exp: exp, Links: map[string]string{}}
+	for i, format := range shareFormats {
+		if mask&(1<<i) != 0 {
+			res.Links[format] = basePath + sharePaths[format][0] + "?" + values
+		}
+	}
+
+	log.Debug().Str("stream", src).Int64("exp", exp).Msg("[api] new share link")
+
+	ResponseJSON(w, res)
+}
+
+// shareUser return user with view permission for the stream from the valid share link
+func shareUser(r *http.Request) *users.User {
+	query := r.URL.Query()
+
+	// share link can't be used with several streams or for publishing
+	if len(query["src"]) != 1 || query.Has("dst") {
+		return nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(query.Get("token"))
+	if err != nil || len(b) != 1+sha256.Size {
+		return nil
+	}
+
+	exp, err := strconv.ParseInt(query.Get("exp"), 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return nil
+	}
+
+	src := query.Get("src")
+	mask := b[0]
+
+	if !hmac.Equal(b, shareSign(src, mask, exp)) {
+		return nil
+	}
+
+	urlPath := strings.TrimPrefix(r.URL.Path, basePath)
+
+	for i, format := range shareFormats {
+		if mask&(1<<i) != 0 && sharePathMatch(sharePaths[format], urlPath) {
+			return &users.User{View: []string{src}}
+		}
+	}
+
+	return nil
+}
+
+func sharePathMatch(paths []string, urlPath string) bool {
+	for _, s := range paths {
+		if s == urlPath || strings.HasSuffix(s, "/") && strings.HasPrefix(urlPath, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShareQuery return share link params from the request for the nested links (ex. HLS playlists)
+func ShareQuery(r *http.Request) string {
+	query := r.URL.Query()
+	if !query.Has("token") {
+		return ""
+	}
+	return "&" + url.Values{
+		"src":   query["src"],
+		"exp":   query["exp"],
+		"token": query["token"],
+	}.Encode()
+}
+
+func shareSign(src string, mask byte, exp int64) []byte {
+	h := hmac.New(sha256.New, shareSecret)
+	_, _ = fmt.Fprintf(h, "%s\n%d\n%d", src, mask, exp)
+	return h.Sum([]byte{mask})
+}
+
+// parseTTL support Go duration (1h30m) or seconds
+func parseTTL(s string) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return time.Duration(i) * time.Second
+	}
+	return 0
+}
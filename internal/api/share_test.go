@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShare(t *testing.T) {
+	shareSecret = []byte("secret")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/api/share?src=camera1&formats=mp4,hls&ttl=60", nil)
+	shareHandler(w, r)
+	require.Equal(t, 200, w.Code)
+
+	var res shareResponse
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Len(t, res.Links, 2)
+	require.InDelta(t, time.Now().Add(time.Minute).Unix(), res.Exp, 1)
+
+	query := "?src=camera1&exp=" + strconv.FormatInt(res.Exp, 10) + "&token=" + res.Token
+
+	user := shareUser(httptest.NewRequest("GET", res.Links["mp4"], nil))
+	require.NotNil(t, user)
+	require.True(t, user.Allow("view", "camera1"))
+	require.False(t, user.Allow("view", "camera2"))
+	require.False(t, user.Allow("backchannel", "camera1"))
+
+	r = httptest.NewRequest("GET", "/api/hls/segment.ts"+query+"&id=123", nil)
+	require.NotNil(t, shareUser(r))
+
+	// format not in the token
+	r = httptest.NewRequest("GET", "/api/frame.jpeg"+query, nil)
+	require.Nil(t, shareUser(r))
+
+	// other stream
+	r = httptest.NewRequest("GET", "/api/stream.mp4?src=camera2&exp="+strconv.FormatInt(res.Exp, 10)+"&token="+res.Token, nil)
+	require.Nil(t, shareUser(r))
+
+	// changed expiration
+	r = httptest.NewRequest("GET", "/api/stream.mp4?src=camera1&exp="+strconv.FormatInt(res.Exp+3600, 10)+"&token="+res.Token, nil)
+	require.Nil(t, shareUser(r))
+
+	// several streams
+	r = httptest.NewRequest("GET", "/api/stream.mp4"+query+"&src=camera2", nil)
+	require.Nil(t, shareUser(r))
+
+	// expired
+	exp := time.Now().Add(-time.Second).Unix()
+	token := base64.RawURLEncoding.EncodeToString(shareSign("camera1", 1, exp))
+	r = httptest.NewRequest("GET", "/api/stream.mp4?src=camera1&exp="+strconv.FormatInt(exp, 10)+"&token="+token, nil)
+	require.Nil(t, shareUser(r))
+
+	// wrong ttl
+	w = httptest.NewRecorder()
+	shareHandler(w, httptest.NewRequest("POST", "/api/share?src=camera1&ttl=-1s", nil))
+	require.Equal(t, 400, w.Code)
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/AlexxIT/go2rtc/internal/users"
+)
+
+// authorize checks user permissions for the stream names from the request query
+func authorize(r *http.Request, user *users.User) bool {
+	if user.IsAdmin() {
+		return true
+	}
+
+	urlPath := strings.TrimPrefix(r.URL.Path, basePath)
+
+	// static UI files are available for all users
+	if !strings.HasPrefix(urlPath, "/api") {
+		return urlPath == "/" || strings.Contains(path.Base(urlPath), ".")
+	}
+
+	query := r.URL.Query()
+
+	// streams config can be changed only by the stream admin
+	if urlPath == "/api/streams" && r.Method != "GET" {
+		names := query["src"]
+		if name := query.Get("name"); name != "" {
+			names = append(names, name)
+		}
+		if r.Method == "POST" {
+			// redirect stream source to the dst stream or stream to the dst source
+			return allowAll(user, users.PermView, query["src"]) && allowAll(user, users.PermPublish, query["dst"])
+		}
+		return len(names) > 0 && allowAll(user, users.PermAdmin, names)
+	}
+
+	// share links can be created only by the stream admin
+	if urlPath == "/api/share" {
+		return query.Has("src") && allowAll(user, users.PermAdmin, query["src"])
+	}
+
+	// recording, preload and clips can be changed only by the stream admin
+	switch urlPath {
+	case "/api/record", "/api/preload", "/api/clip":
+		if r.Method != "GET" {
+			return query.Has("src") && allowAll(user, users.PermAdmin, query["src"])
+		}
+	case "/api/ptz":
+		if r.Method != "GET" {
+			return query.Has("src") && allowAll(user, users.PermPTZ, query["src"])
+		}
+	case "/api/ffmpeg":
+		// file, live and text params are ffmpeg sources
+		return query.Has("dst") && allowAll(user, users.PermAdmin, query["dst"])
+	}
+
+	if query.Has("src") || query.Has("dst") {
+		return allowAll(user, users.PermView, query["src"]) && allowAll(user, users.PermPublish, query["dst"])
+	}
+
+	switch urlPath {
+	case "/api", "/api/ws", "/api/streams", "/api/streams/feed", "/api/schemes":
+		// streams list and feed are filtered by user permissions
+		return true
+	}
+
+	// HLS and WebRTC (WHIP/WHEP resource) session requests use random session ID
+	return strings.HasPrefix(urlPath, "/api/hls/") || strings.HasPrefix(urlPath, "/api/webrtc/")
+}
+
+func allowAll(user *users.User, perm string, names []string) bool {
+	for _, name := range names {
+		// sources (URLs) can be used only by the admin of all streams
+		if strings.Contains(name, ":") || !user.Allow(perm, name) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AlexxIT/go2rtc/internal/users"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	user := &users.User{View: []string{"camera1"}, PTZ: []string{"camera3"}, Admin: []string{"camera2"}}
+
+	require.True(t, authorize(httptest.NewRequest("GET", "/api/frame.jpeg?src=camera1", nil), user))
+	require.False(t, authorize(httptest.NewRequest("GET", "/api/frame.jpeg?src=camera4", nil), user))
+
+	// viewer can read, but can't change recording, preload, clips and PTZ
+	require.True(t, authorize(httptest.NewRequest("GET", "/api/ptz?src=camera1", nil), user))
+	for _, path := range []string{"/api/record", "/api/preload", "/api/clip", "/api/ptz"} {
+		require.False(t, authorize(httptest.NewRequest("PUT", path+"?src=camera1", nil), user), path)
+		require.False(t, authorize(httptest.NewRequest("POST", path, nil), user), path)
+		require.True(t, authorize(httptest.NewRequest("POST", path+"?src=camera2", nil), user), path)
+	}
+
+	// PTZ permission allows camera control without stream admin
+	require.True(t, authorize(httptest.NewRequest("POST", "/api/ptz?src=camera3&action=stop", nil), user))
+	require.False(t, authorize(httptest.NewRequest("POST", "/api/record?src=camera3", nil), user))
+
+	// ffmpeg sources can be played only by the stream admin
+	user.Publish = []string{"camera1"}
+	require.True(t, authorize(httptest.NewRequest("POST", "/api/streams?dst=camera1&src=camera1", nil), user))
+	require.False(t, authorize(httptest.NewRequest("POST", "/api/ffmpeg?dst=camera1&file=/etc/shadow", nil), user))
+	require.False(t, authorize(httptest.NewRequest("POST", "/api/ffmpeg?dst=camera1&live=http://169.254.169.254/", nil), user))
+	require.False(t, authorize(httptest.NewRequest("POST", "/api/ffmpeg?file=/etc/shadow", nil), user))
+	require.True(t, authorize(httptest.NewRequest("POST", "/api/ffmpeg?dst=camera2&text=hello", nil), user))
+}
@@ -77,7 +77,7 @@ func handlerStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session := NewSession(cons)
+	session := NewSession(cons, api.ShareQuery(r))
 	session.alive = time.AfterFunc(keepalive, func() {
 		sessionsMu.Lock()
 		delete(sessions, session.id)
@@ -19,35 +19,41 @@ type Session struct {
 	buffer   []byte
 	seq      int
 	alive    *time.Timer
+	query    string
 	mu       sync.Mutex
 }
 
-func NewSession(cons core.Consumer) *Session {
+// NewSession with optional query for the nested links (ex. share link params)
+func NewSession(cons core.Consumer, query string) *Session {
 	s := &Session{
-		id:   core.RandString(8, 62),
-		cons: cons,
+		id:    core.RandString(8, 62),
+		cons:  cons,
+		query: query,
 	}
 
+	// escape query for the template
+	id := s.id + strings.ReplaceAll(query, "%", "%%")
+
 	// two segments important for Chromecast
 	if _, ok := cons.(*mp4.Consumer); ok {
 		s.template = `#EXTM3U
 #EXT-X-VERSION:6
 #EXT-X-TARGETDURATION:1
 #EXT-X-MEDIA-SEQUENCE:%d
-#EXT-X-MAP:URI="init.mp4?id=` + s.id + `"
+#EXT-X-MAP:URI="init.mp4?id=` + id + `"
 #EXTINF:0.500,
-segment.m4s?id=` + s.id + `&n=%d
+segment.m4s?id=` + id + `&n=%d
 #EXTINF:0.500,
-segment.m4s?id=` + s.id + `&n=%d`
+segment.m4s?id=` + id + `&n=%d`
 	} else {
 		s.template = `#EXTM3U
 #EXT-X-VERSION:3
 #EXT-X-TARGETDURATION:1
 #EXT-X-MEDIA-SEQUENCE:%d
 #EXTINF:0.500,
-segment.ts?id=` + s.id + `&n=%d
+segment.ts?id=` + id + `&n=%d
 #EXTINF:0.500,
-segment.ts?id=` + s.id + `&n=%d`
+segment.ts?id=` + id + `&n=%d`
 	}
 
 	return s
@@ -79,7 +85,7 @@ func (s *Session) Main() []byte {
 	// bandwidth important for Safari, codecs useful for smooth playback
 	return []byte(`#EXTM3U
 #EXT-X-STREAM-INF:BANDWIDTH=192000,CODECS="` + codecs + `"
-hls/playlist.m3u8?id=` + s.id)
+hls/playlist.m3u8?id=` + s.id + s.query)
 }
 
 func (s *Session) Playlist() []byte {
@@ -12,6 +12,7 @@ import (
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/app"
 	"github.com/AlexxIT/go2rtc/internal/ffmpeg"
+	"github.com/AlexxIT/go2rtc/internal/record"
 	"github.com/AlexxIT/go2rtc/internal/srtp"
 	"github.com/AlexxIT/go2rtc/internal/streams"
 	"github.com/AlexxIT/go2rtc/pkg/core"
@@ -125,7 +126,9 @@ func Init() {
 				}
 				log.Info().Str("stream", id).Str("onvif_url", onvifURL).
 					Dur("hold_time", holdTime).Msg("[homekit] starting ONVIF motion watcher")
-				startOnvifMotionWatcher(srv, onvifURL, holdTime, log)
+				startOnvifMotionWatcher(srv, onvifURL, holdTime, log, func() {
+					record.OnMotion(id)
+				})
 			}
 		}
 
@@ -164,6 +167,14 @@ func (p *go2rtcStreamProvider) AddConsumer(name string, cons core.Consumer) erro
 	return stream.AddConsumer(cons)
 }
 
+func (p *go2rtcStreamProvider) AddConsumerPreroll(name string, cons core.Consumer, duration time.Duration) error {
+	stream := streams.Get(name)
+	if stream == nil {
+		return errors.New("stream not found: " + name)
+	}
+	return stream.AddConsumerPreroll(cons, duration)
+}
+
 func (p *go2rtcStreamProvider) RemoveConsumer(name string, cons core.Consumer) {
 	if s := streams.Get(name); s != nil {
 		s.RemoveConsumer(cons)
@@ -402,6 +413,7 @@ func apiMotion(w http.ResponseWriter, r *http.Request) {
 		})
 	case "POST":
 		srv.SetMotionDetected(true)
+		record.OnMotion(id)
 	case "DELETE":
 		srv.SetMotionDetected(false)
 	default:
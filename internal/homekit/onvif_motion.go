@@ -34,6 +34,7 @@ type onvifMotionWatcher struct {
 	onvifURL string
 	holdTime time.Duration
 	log      zerolog.Logger
+	onMotion func() // optional, called on every motion event
 
 	now                 func() time.Time
 	newPullPoint        onvifPullPointFactory
@@ -63,8 +64,9 @@ func newOnvifMotionWatcher(srv *hksv.Server, onvifURL string, holdTime time.Dura
 }
 
 // startOnvifMotionWatcher creates and starts a new ONVIF motion watcher.
-func startOnvifMotionWatcher(srv *hksv.Server, onvifURL string, holdTime time.Duration, log zerolog.Logger) *onvifMotionWatcher {
+func startOnvifMotionWatcher(srv *hksv.Server, onvifURL string, holdTime time.Duration, log zerolog.Logger, onMotion func()) *onvifMotionWatcher {
 	w := newOnvifMotionWatcher(srv, onvifURL, holdTime, log)
+	w.onMotion = onMotion
 	go w.run()
 	return w
 }
@@ -219,6 +221,10 @@ func (w *onvifMotionWatcher) connectAndPoll() error {
 				w.log.Trace().Msg("[homekit] onvif motion: still active, resetting hold timer")
 			}
 
+			if w.onMotion != nil {
+				w.onMotion()
+			}
+
 			// Reset hold timer on every motion=true event.
 			if holdTimer != nil {
 				holdTimer.Stop()
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/pkg/shell"
+)
+
+func Init() {
+	api.HandleFunc("api/metrics", apiMetrics)
+}
+
+// HandleFunc register metrics collector from any module
+func HandleFunc(collector func(m *Metrics)) {
+	collectorsMu.Lock()
+	collectors = append(collectors, collector)
+	collectorsMu.Unlock()
+}
+
+var collectors []func(m *Metrics)
+var collectorsMu sync.Mutex
+
+// Metrics - writer for Prometheus text format
+type Metrics struct {
+	families []*family
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     string
+	samples []byte
+}
+
+// Gauge adds value that can go up and down, labels are key-value pairs
+func (m *Metrics) Gauge(name, help string, value float64, labels ...string) {
+	m.add(name, help, "gauge", value, labels)
+}
+
+// Counter adds value that only goes up (resets on restart), labels are key-value pairs
+func (m *Metrics) Counter(name, help string, value float64, labels ...string) {
+	m.add(name, help, "counter", value, labels)
+}
+
+func (m *Metrics) add(name, help, typ string, value float64, labels []string) {
+	var f *family
+	for _, item := range m.families {
+		if item.name == name {
+			f = item
+			break
+		}
+	}
+	if f == nil {
+		f = &family{name: name, help: help, typ: typ}
+		m.families = append(m.families, f)
+	}
+
+	f.samples = append(f.samples, name...)
+	if len(labels) > 1 {
+		f.samples = append(f.samples, '{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				f.samples = append(f.samples, ',')
+			}
+			f.samples = append(f.samples, labels[i]+`="`+escape(labels[i+1])+`"`...)
+		}
+		f.samples = append(f.samples, '}')
+	}
+	f.samples = append(f.samples, ' ')
+	f.samples = strconv.AppendFloat(f.samples, value, 'g', -1, 64)
+	f.samples = append(f.samples, '\n')
+}
+
+func (m *Metrics) Bytes() []byte {
+	var b []byte
+	for _, f := range m.families {
+		b = append(b, "# HELP "+f.name+" "+f.help+"\n"...)
+		b = append(b, "# TYPE "+f.name+" "+f.typ+"\n"...)
+		b = append(b, f.samples...)
+	}
+	return b
+}
+
+var replacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return replacer.Replace(s)
+}
+
+func apiMetrics(w http.ResponseWriter, r *http.Request) {
+	m := &Metrics{}
+
+	m.Gauge("go2rtc_info", "Application info", 1, "version", app.Version)
+	m.Gauge("go2rtc_goroutines", "Number of goroutines", float64(runtime.NumGoroutine()))
+
+	for name, n := range shell.Running() {
+		m.Gauge("go2rtc_processes", "Number of running processes (ffmpeg, exec)", float64(n), "name", name)
+	}
+
+	collectorsMu.Lock()
+	for _, collector := range collectors {
+		collector(m)
+	}
+	collectorsMu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(m.Bytes())
+}
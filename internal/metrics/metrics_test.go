@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m := &Metrics{}
+	m.Gauge("go2rtc_stream_consumers", "Number of consumers", 2, "stream", "camera1")
+	m.Counter("go2rtc_receiver_bytes_total", "Bytes received", 1024, "stream", "camera1", "codec", "H264")
+	m.Gauge("go2rtc_stream_consumers", "Number of consumers", 0, "stream", `cam"2`)
+
+	require.Equal(t, `# HELP go2rtc_stream_consumers Number of consumers
+# TYPE go2rtc_stream_consumers gauge
+go2rtc_stream_consumers{stream="camera1"} 2
+go2rtc_stream_consumers{stream="cam\"2"} 0
+# HELP go2rtc_receiver_bytes_total Bytes received
+# TYPE go2rtc_receiver_bytes_total counter
+go2rtc_receiver_bytes_total{stream="camera1",codec="H264"} 1024
+`, string(m.Bytes()))
+}
@@ -103,7 +103,13 @@ func handlerMP4(w http.ResponseWriter, r *http.Request) {
 	cons.Protocol = "http"
 	cons.WithRequest(r)
 
-	if err := stream.AddConsumer(cons); err != nil {
+	var err error
+	if preroll := query.Get("preroll"); preroll != "" {
+		err = stream.AddConsumerPreroll(cons, streams.ParseDuration(preroll))
+	} else {
+		err = stream.AddConsumer(cons)
+	}
+	if err != nil {
 		log.Error().Err(err).Caller().Send()
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
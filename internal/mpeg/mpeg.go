@@ -4,14 +4,49 @@ import (
 	"net/http"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/app"
 	"github.com/AlexxIT/go2rtc/internal/streams"
 	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/mpegts"
+	"github.com/rs/zerolog"
 )
 
 func Init() {
+	log = app.GetLogger("mpegts")
+
 	api.HandleFunc("api/stream.ts", apiHandle)
 	api.HandleFunc("api/stream.aac", apiStreamAAC)
+
+	streams.HandleFunc("udp", func(source string) (core.Producer, error) {
+		return mpegts.OpenUDP(source)
+	})
+
+	streams.HandleConsumerFunc("udp", streamsConsumerHandle)
+}
+
+var log zerolog.Logger
+
+// streamsConsumerHandle - publish stream to the UDP address or the multicast group
+func streamsConsumerHandle(rawURL string) (core.Consumer, func(), error) {
+	cons := mpegts.NewConsumer()
+	cons.Protocol = "udp"
+	cons.URL = rawURL
+
+	run := func() {
+		wr, err := mpegts.DialUDP(rawURL)
+		if err != nil {
+			log.Error().Err(err).Str("url", rawURL).Msg("[mpegts] publish")
+			return
+		}
+
+		cons.RemoteAddr = wr.RemoteAddr().String()
+
+		_, _ = cons.WriteTo(wr)
+		_ = wr.Close()
+	}
+
+	return cons, run, nil
 }
 
 func apiHandle(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,116 @@
+package onvif
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/users"
+	"github.com/AlexxIT/go2rtc/pkg/onvif"
+)
+
+var authUsername, authPassword string
+var authLocal bool
+
+// preAuthOperations - available without credentials (ONVIF Core Specification, 5.9.4.3 Access classes)
+var preAuthOperations = map[string]bool{
+	onvif.ServiceGetServiceCapabilities: true,
+	onvif.DeviceGetCapabilities:         true,
+	onvif.DeviceGetHostname:             true,
+	onvif.DeviceGetServices:             true,
+	onvif.DeviceGetSystemDateAndTime:    true,
+}
+
+func authEnabled() bool {
+	return authUsername != "" || users.Enabled()
+}
+
+// authenticate checks WS-Security UsernameToken (or HTTP Basic) credentials from the request.
+// Return nil user for full access (auth disabled, localhost or global credentials).
+func authenticate(r *http.Request, b []byte, operation string) (user *users.User, ok bool) {
+	if !authEnabled() || preAuthOperations[operation] {
+		return nil, true
+	}
+
+	// skip check auth for localhost
+	if !authLocal {
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+				return nil, true
+			}
+		}
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		if authUsername != "" && username == authUsername &&
+			subtle.ConstantTimeCompare([]byte(password), []byte(authPassword)) == 1 {
+			return nil, true
+		}
+		if user = users.Auth(username, password); user != nil {
+			return user, true
+		}
+		return nil, false
+	}
+
+	token := onvif.GetUsernameToken(b)
+	if token == nil {
+		return nil, false
+	}
+
+	now := time.Now()
+
+	if authUsername != "" && token.Username == authUsername && token.Validate(authPassword, now) {
+		return nil, useNonce(token, now)
+	}
+
+	if user = users.Get(token.Username); user != nil && token.Validate(user.Password, now) {
+		return user, useNonce(token, now)
+	}
+
+	return nil, false
+}
+
+// nonceTTL - covers the allowed Created time window in both directions
+const nonceTTL = 10 * time.Minute
+
+var nonces = map[string]time.Time{}
+var noncesMu sync.Mutex
+
+// useNonce rejects replay of the digest token with the same nonce
+func useNonce(token *onvif.UsernameToken, now time.Time) bool {
+	if !token.Digest {
+		return true
+	}
+
+	noncesMu.Lock()
+	defer noncesMu.Unlock()
+
+	for nonce, ts := range nonces {
+		if now.Sub(ts) > nonceTTL {
+			delete(nonces, nonce)
+		}
+	}
+
+	if _, ok := nonces[token.Nonce]; ok {
+		return false
+	}
+
+	nonces[token.Nonce] = now
+	return true
+}
+
+// allowedNames filter stream names by user view permission
+func allowedNames(user *users.User, names []string) []string {
+	if user == nil {
+		return names
+	}
+	var allowed []string
+	for _, name := range names {
+		if user.Allow(users.PermView, name) {
+			allowed = append(allowed, name)
+		}
+	}
+	return allowed
+}
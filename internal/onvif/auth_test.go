@@ -0,0 +1,20 @@
+package onvif
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/onvif"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseNonce(t *testing.T) {
+	now := time.Now()
+	token := &onvif.UsernameToken{Nonce: "bm9uY2U=", Digest: true}
+
+	require.True(t, useNonce(token, now))
+	require.False(t, useNonce(token, now.Add(time.Minute)), "replay")
+
+	// nonce is forgotten after the Created window
+	require.True(t, useNonce(token, now.Add(nonceTTL+time.Second)))
+}
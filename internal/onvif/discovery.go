@@ -0,0 +1,76 @@
+package onvif
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/pkg/onvif"
+)
+
+// discoveryServe - WS-Discovery responder, so NVRs can find go2rtc as ONVIF device
+func discoveryServe() {
+	addr := &net.UDPAddr{
+		IP:   net.IP{239, 255, 255, 250},
+		Port: 3702,
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", nil, addr)
+	if err != nil {
+		log.Error().Err(err).Msg("[onvif] discovery listen")
+		return
+	}
+
+	log.Info().Str("addr", addr.String()).Msg("[onvif] discovery listen")
+
+	uuid := deviceUUID()
+
+	b := make([]byte, 8192)
+	for {
+		n, remote, err := conn.ReadFromUDP(b)
+		if err != nil {
+			return
+		}
+
+		if !onvif.IsDiscoveryProbe(b[:n]) {
+			continue
+		}
+
+		// answer with the address from the client subnet
+		host := localIP(remote)
+		if host == "" {
+			continue
+		}
+
+		xaddr := "http://" + net.JoinHostPort(host, strconv.Itoa(api.Port)) + onvif.PathDevice
+		messageID := html.EscapeString(onvif.FindTagValue(b[:n], "MessageID"))
+
+		log.Trace().Msgf("[onvif] discovery probe from %s", remote)
+
+		res := onvif.GetProbeMatchesResponse(messageID, uuid, "go2rtc", xaddr)
+		if _, err = conn.WriteToUDP(res, remote); err != nil {
+			log.Warn().Err(err).Caller().Send()
+		}
+	}
+}
+
+// localIP return local address for the route to the remote address
+func localIP(remote *net.UDPAddr) string {
+	conn, err := net.DialUDP("udp4", nil, remote)
+	if err != nil {
+		return ""
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String()
+}
+
+// deviceUUID - stable endpoint ID for the same host and port
+func deviceUUID() string {
+	hostname, _ := os.Hostname()
+	h := sha1.Sum([]byte(hostname + ":" + strconv.Itoa(api.Port)))
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
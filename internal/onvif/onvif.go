@@ -14,14 +14,33 @@ import (
 	"github.com/AlexxIT/go2rtc/internal/app"
 	"github.com/AlexxIT/go2rtc/internal/rtsp"
 	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/internal/users"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/onvif"
 	"github.com/rs/zerolog"
 )
 
 func Init() {
+	var cfg struct {
+		Mod struct {
+			Discovery bool `yaml:"discovery"`
+		} `yaml:"onvif"`
+		API struct {
+			Username  string `yaml:"username"`
+			Password  string `yaml:"password"`
+			LocalAuth bool   `yaml:"local_auth"`
+		} `yaml:"api"`
+	}
+
+	app.LoadConfig(&cfg)
+
 	log = app.GetLogger("onvif")
 
+	// ONVIF server uses the same credentials as API
+	authUsername = cfg.API.Username
+	authPassword = cfg.API.Password
+	authLocal = cfg.API.LocalAuth
+
 	streams.HandleFunc("onvif", streamOnvif)
 
 	// ONVIF server on all suburls
@@ -29,6 +48,13 @@ func Init() {
 
 	// ONVIF client autodiscovery
 	api.HandleFunc("api/onvif", apiOnvif)
+
+	// PTZ control for streams with onvif source
+	api.HandleFunc("api/ptz", apiPTZ)
+
+	if cfg.Mod.Discovery && api.Port != 0 {
+		go discoveryServe()
+	}
 }
 
 var log zerolog.Logger
@@ -73,6 +99,13 @@ func onvifDeviceService(w http.ResponseWriter, r *http.Request) {
 
 	log.Trace().Msgf("[onvif] server request %s %s:\n%s", r.Method, r.RequestURI, b)
 
+	user, ok := authenticate(r, b, operation)
+	if !ok {
+		log.Debug().Msgf("[onvif] not authorized operation=%s remote_addr=%s", operation, r.RemoteAddr)
+		writeFault(w, onvif.NotAuthorizedResponse())
+		return
+	}
+
 	switch operation {
 	case onvif.ServiceGetServiceCapabilities, // important for Hass
 		onvif.DeviceGetNetworkInterfaces, // important for Hass
@@ -85,26 +118,26 @@ func onvifDeviceService(w http.ResponseWriter, r *http.Request) {
 		onvif.DeviceGetNetworkProtocols,
 		onvif.DeviceGetNTP,
 		onvif.DeviceGetScopes,
-		onvif.MediaGetVideoEncoderConfiguration,
-		onvif.MediaGetVideoEncoderConfigurations,
-		onvif.MediaGetAudioEncoderConfigurations,
-		onvif.MediaGetVideoEncoderConfigurationOptions,
-		onvif.MediaGetAudioSources,
-		onvif.MediaGetAudioSourceConfigurations:
+		onvif.MediaGetVideoEncoderConfigurationOptions:
 		b = onvif.StaticResponse(operation)
 
 	case onvif.DeviceGetCapabilities:
 		// important for Hass: Media section
-		b = onvif.GetCapabilitiesResponse(r.Host)
+		b = onvif.GetCapabilitiesResponse(r.Host, hasPTZ())
 
 	case onvif.DeviceGetServices:
-		b = onvif.GetServicesResponse(r.Host)
+		b = onvif.GetServicesResponse(r.Host, hasPTZ())
 
 	case onvif.DeviceGetDeviceInformation:
 		// important for Hass: SerialNumber (unique server ID)
 		b = onvif.GetDeviceInformationResponse("", "go2rtc", app.Version, r.Host)
 
 	case onvif.DeviceSystemReboot:
+		if !user.IsAdmin() {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
 		b = onvif.StaticResponse(operation)
 
 		time.AfterFunc(time.Second, func() {
@@ -112,23 +145,61 @@ func onvifDeviceService(w http.ResponseWriter, r *http.Request) {
 		})
 
 	case onvif.MediaGetVideoSources:
-		b = onvif.GetVideoSourcesResponse(streams.GetAllNames())
+		b = onvif.GetVideoSourcesResponse(userProfiles(user))
 
 	case onvif.MediaGetProfiles:
 		// important for Hass: H264 codec, width, height
-		b = onvif.GetProfilesResponse(streams.GetAllNames())
+		b = onvif.GetProfilesResponse(userProfiles(user))
 
 	case onvif.MediaGetProfile:
 		token := onvif.FindTagValue(b, "ProfileToken")
-		b = onvif.GetProfileResponse(token)
+		if !allowedProfile(user, token) {
+			writeFault(w, onvif.NoProfileResponse())
+			return
+		}
+		b = onvif.GetProfileResponse(getProfile(token))
 
 	case onvif.MediaGetVideoSourceConfigurations:
 		// important for Happytime Onvif Client
-		b = onvif.GetVideoSourceConfigurationsResponse(streams.GetAllNames())
+		b = onvif.GetVideoSourceConfigurationsResponse(userProfiles(user))
 
 	case onvif.MediaGetVideoSourceConfiguration:
 		token := onvif.FindTagValue(b, "ConfigurationToken")
-		b = onvif.GetVideoSourceConfigurationResponse(token)
+		if !allowedProfile(user, token) {
+			writeFault(w, onvif.NoConfigResponse())
+			return
+		}
+		b = onvif.GetVideoSourceConfigurationResponse(getProfile(token))
+
+	case onvif.MediaGetVideoEncoderConfigurations:
+		b = onvif.GetVideoEncoderConfigurationsResponse(userProfiles(user))
+
+	case onvif.MediaGetVideoEncoderConfiguration:
+		token := onvif.FindTagValue(b, "ConfigurationToken")
+		if !allowedProfile(user, token) {
+			writeFault(w, onvif.NoConfigResponse())
+			return
+		}
+		b = onvif.GetVideoEncoderConfigurationResponse(getProfile(token))
+
+	case onvif.MediaGetAudioSources:
+		b = onvif.GetAudioSourcesResponse(userProfiles(user))
+
+	case onvif.MediaGetAudioSourceConfigurations:
+		b = onvif.GetAudioSourceConfigurationsResponse(userProfiles(user))
+
+	case onvif.MediaGetAudioEncoderConfigurations:
+		b = onvif.GetAudioEncoderConfigurationsResponse(userProfiles(user))
+
+	case onvif.MediaGetAudioOutputs:
+		// important for two-way audio
+		b = onvif.GetAudioOutputsResponse(userProfiles(user))
+
+	case onvif.MediaGetAudioOutputConfigurations:
+		b = onvif.GetAudioOutputConfigurationsResponse(userProfiles(user))
+
+	case onvif.MediaGetAudioDecoderConfigurations:
+		b = onvif.GetAudioDecoderConfigurationsResponse(userProfiles(user))
 
 	case onvif.MediaGetStreamUri:
 		host, _, err := net.SplitHostPort(r.Host)
@@ -136,13 +207,40 @@ func onvifDeviceService(w http.ResponseWriter, r *http.Request) {
 			host = r.Host // in case of Host without port
 		}
 
-		uri := "rtsp://" + host + ":" + rtsp.Port + "/" + onvif.FindTagValue(b, "ProfileToken")
+		token := onvif.FindTagValue(b, "ProfileToken")
+		if !user.Allow(users.PermView, token) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		uri := "rtsp://" + host + ":" + rtsp.Port + "/" + token
 		b = onvif.GetStreamUriResponse(uri)
 
 	case onvif.MediaGetSnapshotUri:
-		uri := "http://" + r.Host + "/api/frame.jpeg?src=" + onvif.FindTagValue(b, "ProfileToken")
+		token := onvif.FindTagValue(b, "ProfileToken")
+		if !user.Allow(users.PermView, token) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		uri := "http://" + r.Host + "/api/frame.jpeg?src=" + token
 		b = onvif.GetSnapshotUriResponse(uri)
 
+	case onvif.PTZContinuousMove,
+		onvif.PTZGetConfigurations,
+		onvif.PTZGetConfigurationOptions,
+		onvif.PTZGetNodes,
+		onvif.PTZGetPresets,
+		onvif.PTZGotoPreset,
+		onvif.PTZRemovePreset,
+		onvif.PTZSetPreset,
+		onvif.PTZStop:
+		if b, err = ptzRequest(operation, b, user); err != nil {
+			log.Warn().Err(err).Str("operation", operation).Msg("[onvif] ptz")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 	default:
 		http.Error(w, "unsupported operation", http.StatusBadRequest)
 		log.Warn().Msgf("[onvif] unsupported operation: %s", operation)
@@ -158,6 +256,13 @@ func onvifDeviceService(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writeFault - SOAP 1.2 sender faults are sent with 400 status code
+func writeFault(w http.ResponseWriter, b []byte) {
+	w.Header().Set("Content-Type", "application/soap+xml; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(b)
+}
+
 func apiOnvif(w http.ResponseWriter, r *http.Request) {
 	src := r.URL.Query().Get("src")
 
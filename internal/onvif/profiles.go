@@ -0,0 +1,81 @@
+package onvif
+
+import (
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/internal/users"
+	"github.com/AlexxIT/go2rtc/pkg/onvif"
+)
+
+// profileTTL - ONVIF clients request profiles very often, so don't check the PTZ source every time
+const profileTTL = time.Minute
+
+type cachedProfile struct {
+	profile *onvif.Profile
+	expires time.Time
+}
+
+var profiles = map[string]cachedProfile{}
+var profilesMu sync.Mutex
+
+// getProfile return ONVIF profile with codecs of running or previously probed stream sources
+func getProfile(name string) *onvif.Profile {
+	now := time.Now()
+
+	profilesMu.Lock()
+	cached, ok := profiles[name]
+	profilesMu.Unlock()
+
+	if ok && now.Before(cached.expires) {
+		return cached.profile
+	}
+
+	var profile *onvif.Profile
+	if stream := streams.Get(name); stream != nil {
+		profile = onvif.NewProfile(name, stream.GetMedias())
+	} else {
+		profile = onvif.NewProfile(name, nil)
+	}
+
+	if ptzSource(name) != "" {
+		_, err := getPTZ(name)
+		profile.PTZ = err == nil
+	}
+
+	log.Trace().Msgf("[onvif] profile %+v", profile)
+
+	profilesMu.Lock()
+	profiles[name] = cachedProfile{profile: profile, expires: now.Add(profileTTL)}
+	profilesMu.Unlock()
+
+	return profile
+}
+
+// allowedProfile - unknown and forbidden streams look the same for the client
+func allowedProfile(user *users.User, name string) bool {
+	return streams.Get(name) != nil && user.Allow(users.PermView, name)
+}
+
+// getProfiles check PTZ sources of all streams in parallel
+func getProfiles(names []string) []*onvif.Profile {
+	items := make([]*onvif.Profile, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			items[i] = getProfile(name)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	return items
+}
+
+// userProfiles return profiles for all streams allowed for the user
+func userProfiles(user *users.User) []*onvif.Profile {
+	return getProfiles(allowedNames(user, streams.GetAllNames()))
+}
@@ -0,0 +1,214 @@
+package onvif
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/internal/users"
+	"github.com/AlexxIT/go2rtc/pkg/onvif"
+)
+
+type ptzCamera struct {
+	client *onvif.Client
+	token  string // camera profile token
+
+	err     error     // failed connection to the camera
+	expires time.Time // retry time for the failed camera
+}
+
+// ptzRetry - don't dial unavailable camera on every request
+const ptzRetry = 30 * time.Second
+
+var ptzCameras = map[string]*ptzCamera{} // onvif source => camera
+var ptzMu sync.Mutex
+
+// ptzSource return first onvif:// source of the stream
+func ptzSource(name string) string {
+	stream := streams.Get(name)
+	if stream == nil {
+		return ""
+	}
+	for _, source := range stream.Sources() {
+		if strings.HasPrefix(source, "onvif://") {
+			return source
+		}
+	}
+	return ""
+}
+
+// hasPTZ - any stream has onvif source for PTZ control
+func hasPTZ() bool {
+	for _, sources := range streams.GetAllSources() {
+		for _, source := range sources {
+			if strings.HasPrefix(source, "onvif://") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getPTZ(name string) (*ptzCamera, error) {
+	source := ptzSource(name)
+	if source == "" {
+		return nil, errors.New("onvif: stream without onvif source: " + name)
+	}
+
+	ptzMu.Lock()
+	camera := ptzCameras[source]
+	ptzMu.Unlock()
+
+	if camera == nil || (camera.err != nil && time.Now().After(camera.expires)) {
+		// dial without lock, so a slow camera doesn't block other streams
+		camera = dialPTZ(source)
+
+		ptzMu.Lock()
+		ptzCameras[source] = camera
+		ptzMu.Unlock()
+	}
+
+	if camera.err != nil {
+		return nil, camera.err
+	}
+	return camera, nil
+}
+
+func dialPTZ(source string) *ptzCamera {
+	client, err := onvif.NewClient(source)
+	if err == nil {
+		if !client.HasPTZ() {
+			err = errors.New("onvif: camera without PTZ")
+		} else {
+			var token string
+			if token, err = client.GetProfileToken(); err == nil {
+				return &ptzCamera{client: client, token: token}
+			}
+		}
+	}
+	return &ptzCamera{err: err, expires: time.Now().Add(ptzRetry)}
+}
+
+// ptzRequest forward PTZ request for the stream (ProfileToken) to the source camera
+func ptzRequest(operation string, b []byte, user *users.User) ([]byte, error) {
+	switch operation {
+	case onvif.PTZGetNodes, onvif.PTZGetConfigurationOptions:
+		return onvif.PTZStaticResponse(operation), nil
+	case onvif.PTZGetConfigurations:
+		return onvif.GetPTZConfigurationsResponse(userProfiles(user)), nil
+	}
+
+	// presets list is available for viewers, camera control needs PTZ permission
+	perm := users.PermPTZ
+	if operation == onvif.PTZGetPresets {
+		perm = users.PermView
+	}
+
+	name := onvif.FindTagValue(b, "ProfileToken")
+	if !user.Allow(perm, name) {
+		return nil, errors.New("onvif: forbidden stream: " + name)
+	}
+
+	camera, err := getPTZ(name)
+	if err != nil {
+		return nil, err
+	}
+
+	client := camera.client
+
+	switch operation {
+	case onvif.PTZContinuousMove:
+		pan, tilt, zoom := onvif.FindVelocity(b)
+		err = client.ContinuousMove(camera.token, pan, tilt, zoom)
+	case onvif.PTZStop:
+		err = client.Stop(camera.token)
+	case onvif.PTZGetPresets:
+		var presets []onvif.Preset
+		if presets, err = client.GetPresets(camera.token); err != nil {
+			return nil, err
+		}
+		return onvif.GetPresetsResponse(presets), nil
+	case onvif.PTZGotoPreset:
+		err = client.GotoPreset(camera.token, onvif.FindTagValue(b, "PresetToken"))
+	case onvif.PTZSetPreset:
+		var preset string
+		if preset, err = client.SetPreset(camera.token, onvif.FindTagValue(b, "PresetName")); err != nil {
+			return nil, err
+		}
+		return onvif.SetPresetResponse(preset), nil
+	case onvif.PTZRemovePreset:
+		err = client.RemovePreset(camera.token, onvif.FindTagValue(b, "PresetToken"))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return onvif.PTZStaticResponse(operation), nil
+}
+
+// apiPTZ - PTZ control for the web UI:
+//   - GET  api/ptz?src=camera1 - presets list
+//   - POST api/ptz?src=camera1&action=move&pan=0.5&tilt=0&zoom=0
+//   - POST api/ptz?src=camera1&action=stop
+//   - POST api/ptz?src=camera1&action=goto&preset=1
+//   - POST api/ptz?src=camera1&action=set&name=door
+//   - POST api/ptz?src=camera1&action=remove&preset=1
+func apiPTZ(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	camera, err := getPTZ(query.Get("src"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	client := camera.client
+
+	if r.Method == "GET" {
+		presets, err := client.GetPresets(camera.token)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		api.ResponseJSON(w, map[string]any{"presets": presets})
+		return
+	}
+
+	if r.Method != "POST" {
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch action := query.Get("action"); action {
+	case "move":
+		pan, _ := strconv.ParseFloat(query.Get("pan"), 64)
+		tilt, _ := strconv.ParseFloat(query.Get("tilt"), 64)
+		zoom, _ := strconv.ParseFloat(query.Get("zoom"), 64)
+		err = client.ContinuousMove(camera.token, pan, tilt, zoom)
+	case "stop":
+		err = client.Stop(camera.token)
+	case "goto":
+		err = client.GotoPreset(camera.token, query.Get("preset"))
+	case "set":
+		var preset string
+		if preset, err = client.SetPreset(camera.token, query.Get("name")); err == nil {
+			api.ResponseJSON(w, onvif.Preset{Token: preset, Name: query.Get("name")})
+			return
+		}
+	case "remove":
+		err = client.RemovePreset(camera.token, query.Get("preset"))
+	default:
+		http.Error(w, "unknown action: "+action, http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
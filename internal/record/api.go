@@ -0,0 +1,279 @@
+package record
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+func apiRecord(w http.ResponseWriter, r *http.Request) {
+	if api.IsReadOnly() {
+		switch r.Method {
+		case "PUT", "DELETE":
+			api.ReadOnlyError(w)
+			return
+		}
+	}
+	// GET - return all records
+	if r.Method == "GET" {
+		api.ResponseJSON(w, GetRecords())
+		return
+	}
+
+	query := r.URL.Query()
+	src := query.Get("src")
+
+	switch r.Method {
+	case "PUT":
+		// it's safe to delete from map while iterating
+		for k := range query {
+			switch k {
+			case core.KindVideo, core.KindAudio:
+			default:
+				delete(query, k)
+			}
+		}
+
+		rawQuery := query.Encode()
+
+		if err := AddRecord(src, rawQuery); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := app.PatchConfig([]string{"record", "streams", src}, rawQuery); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+
+	case "DELETE":
+		if err := DelRecord(src); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := app.PatchConfig([]string{"record", "streams", src}, nil); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+
+	default:
+		http.Error(w, "", http.StatusMethodNotAllowed)
+	}
+}
+
+func apiList(w http.ResponseWriter, r *http.Request) {
+	var names []string
+
+	if query := r.URL.Query(); query.Has("src") {
+		names = query["src"]
+	} else {
+		entries, _ := os.ReadDir(storagePath)
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if name, err := url.PathUnescape(entry.Name()); err == nil {
+				names = append(names, name)
+			}
+		}
+	}
+
+	list := make(map[string][]*Range, len(names))
+	for _, name := range names {
+		list[name] = GetRanges(ReadSegments(StreamPath(name)))
+	}
+
+	api.ResponseJSON(w, list)
+}
+
+func apiPlayback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	segments, start, end, err := parsePlayback(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if segments == nil {
+		http.Error(w, "record: segments not found", http.StatusNotFound)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "video/mp4")
+
+	if filename := query.Get("filename"); filename != "" {
+		header.Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+
+	if err = Playback(w, segments, start, end); err != nil {
+		log.Error().Err(err).Caller().Send()
+	}
+}
+
+func apiPlaylist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		return
+	}
+
+	query := r.URL.Query()
+
+	segments, start, _, err := parsePlayback(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if segments == nil {
+		http.Error(w, "record: segments not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err = w.Write(Playlist(query.Get("src"), segments, start)); err != nil {
+		log.Error().Err(err).Caller().Send()
+	}
+}
+
+func apiInit(w http.ResponseWriter, r *http.Request) {
+	serveSegment(w, r, true)
+}
+
+func apiSegment(w http.ResponseWriter, r *http.Request) {
+	serveSegment(w, r, false)
+}
+
+func serveSegment(w http.ResponseWriter, r *http.Request, init bool) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET")
+		return
+	}
+
+	query := r.URL.Query()
+
+	id := query.Get("id")
+	if _, err := time.Parse(parseLayout, id); err != nil {
+		http.Error(w, "record: wrong segment id", http.StatusBadRequest)
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(StreamPath(query.Get("src")), id+".mp4"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	b, _, _, err := splitSegment(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if init {
+		w.Header().Set("Content-Type", "video/mp4")
+	} else {
+		w.Header().Set("Content-Type", "video/iso.segment")
+		b = data[len(b):]
+	}
+
+	if _, err = w.Write(b); err != nil {
+		log.Error().Err(err).Caller().Send()
+	}
+}
+
+func parsePlayback(query url.Values) (segments []*Segment, start, end time.Time, err error) {
+	src := query.Get("src")
+	if src == "" {
+		err = errors.New("record: source empty")
+		return
+	}
+
+	if start, err = ParseTime(query.Get("start")); err != nil {
+		return
+	}
+
+	if s := query.Get("end"); s != "" {
+		if end, err = ParseTime(s); err != nil {
+			return
+		}
+	} else {
+		end = time.Now()
+	}
+
+	segments = FilterSegments(ReadSegments(StreamPath(src)), start, end)
+	return
+}
+
+func apiClip(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	src := query.Get("src")
+	if src == "" {
+		http.Error(w, "record: source empty", http.StatusBadRequest)
+		return
+	}
+
+	pre, post := clipsPre, clipsPost
+	if s := query.Get("pre"); s != "" {
+		pre = streams.ParseDuration(s)
+	}
+	if s := query.Get("post"); s != "" {
+		post = streams.ParseDuration(s)
+	}
+
+	switch r.Method {
+	case "GET":
+		clip, err := StartClip(src, pre, post)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// clip will be saved even if the client disconnects
+		clip.Wait()
+
+		if err = clip.WaitStart(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		filename := query.Get("filename")
+		if filename == "" {
+			filename = filepath.Base(clip.Path)
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+		http.ServeFile(w, r, clip.Path)
+
+	case "POST":
+		clip, err := StartClip(src, pre, post)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// clip is saved in background, but the response waits for the first keyframe
+		if err = clip.WaitStart(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		api.ResponseJSON(w, clip)
+
+	default:
+		http.Error(w, "", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,198 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/streams"
+)
+
+// Clip - single MP4 file for the event window: pre-roll before the event
+// and post time after the last event trigger
+type Clip struct {
+	Stream string    `json:"stream"`
+	Path   string    `json:"path"`
+	Start  time.Time `json:"start"` // first event time
+	End    time.Time `json:"end"`   // planned end time, extended by new events
+
+	stream  *streams.Stream
+	cons    *Consumer
+	timer   *time.Timer
+	started chan struct{} // closed on the first keyframe
+	done    chan struct{}
+}
+
+var (
+	clipsPath   string
+	clipsPre    time.Duration
+	clipsPost   time.Duration
+	clipsMotion bool
+)
+
+// clips - active clips, one per stream
+var clips = map[string]*Clip{}
+var clipsMu sync.Mutex
+
+// StartClip creates new clip for the stream or extends the active one
+func StartClip(name string, pre, post time.Duration) (*Clip, error) {
+	clipsMu.Lock()
+	defer clipsMu.Unlock()
+
+	now := time.Now()
+
+	if clip := clips[name]; clip != nil {
+		if end := now.Add(post); end.After(clip.End) {
+			clip.End = end
+			clip.timer.Reset(post)
+		}
+		return clip, nil
+	}
+
+	stream := streams.Get(name)
+	if stream == nil {
+		return nil, fmt.Errorf("record: stream not found: %s", name)
+	}
+
+	dir := filepath.Join(clipsPath, escapeName(name))
+
+	clip := &Clip{
+		Stream:  name,
+		Path:    filepath.Join(dir, now.UTC().Format(TimeLayout)+".mp4"),
+		Start:   now,
+		End:     now.Add(post),
+		stream:  stream,
+		cons:    NewConsumer(nil),
+		started: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	clip.cons.Create = func(time.Time) (io.WriteCloser, error) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		f, err := os.Create(clip.Path)
+		if err != nil {
+			return nil, err
+		}
+		// Create is called under the consumer lock, so select is enough
+		select {
+		case <-clip.started:
+		default:
+			close(clip.started)
+		}
+		return f, nil
+	}
+
+	var err error
+	if pre > 0 && stream.Preroll() != nil {
+		err = stream.AddConsumerPreroll(clip.cons, pre)
+	} else {
+		if pre > 0 {
+			log.Warn().Str("stream", name).Msg("[record] clip without preroll buffer")
+		}
+		err = stream.AddConsumer(clip.cons)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clip.timer = time.AfterFunc(post, clip.stop)
+
+	log.Debug().Str("file", clip.Path).Msg("[record] start clip")
+
+	clips[name] = clip
+	return clip, nil
+}
+
+// Wait until clip is finished
+func (c *Clip) Wait() {
+	<-c.done
+}
+
+// WaitStart waits for the first keyframe of the clip and returns an error
+// if the clip was finished without any data
+func (c *Clip) WaitStart() error {
+	select {
+	case <-c.started:
+		return nil
+	case <-c.done:
+	}
+
+	select {
+	case <-c.started:
+		return nil
+	default:
+		return fmt.Errorf("record: clip without keyframe: %s", c.Stream)
+	}
+}
+
+// MarshalJSON - clip End can be extended by new events at any time
+func (c *Clip) MarshalJSON() ([]byte, error) {
+	type clip Clip
+	clipsMu.Lock()
+	v := clip(*c)
+	clipsMu.Unlock()
+	return json.Marshal(&v)
+}
+
+// clipSegments return saved clips for retention, except active ones
+func clipSegments() []*Segment {
+	entries, err := os.ReadDir(clipsPath)
+	if err != nil {
+		return nil
+	}
+
+	active := map[string]bool{}
+	clipsMu.Lock()
+	for _, clip := range clips {
+		active[clip.Path] = true
+	}
+	clipsMu.Unlock()
+
+	var segments []*Segment
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		for _, s := range ReadSegments(filepath.Join(clipsPath, entry.Name())) {
+			if !active[s.Path] {
+				segments = append(segments, s)
+			}
+		}
+	}
+
+	return segments
+}
+
+func (c *Clip) stop() {
+	clipsMu.Lock()
+	// clip may be extended or already stopped
+	if clips[c.Stream] != c || time.Now().Before(c.End) {
+		clipsMu.Unlock()
+		return
+	}
+	delete(clips, c.Stream)
+	clipsMu.Unlock()
+
+	c.stream.RemoveConsumer(c.cons)
+
+	log.Debug().Str("file", c.Path).Msg("[record] stop clip")
+
+	close(c.done)
+}
+
+// OnMotion creates clip for motion event if enabled in config
+func OnMotion(name string) {
+	if !clipsMotion {
+		return
+	}
+	if _, err := StartClip(name, clipsPre, clipsPost); err != nil {
+		log.Warn().Err(err).Str("stream", name).Msg("[record] can't start motion clip")
+	}
+}
@@ -0,0 +1,222 @@
+package record
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/aac"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+	"github.com/AlexxIT/go2rtc/pkg/mp4"
+	"github.com/AlexxIT/go2rtc/pkg/pcm"
+	"github.com/pion/rtp"
+)
+
+// Consumer writes stream to a sequence of MP4 files (segments).
+// Every segment starts with init and a keyframe, so it can be played standalone.
+type Consumer struct {
+	core.Connection
+	muxer *mp4.Muxer
+	mu    sync.Mutex
+	video bool
+
+	// Create called on every new segment with segment start time
+	Create func(start time.Time) (io.WriteCloser, error) `json:"-"`
+	// Duration - min segment length, zero for single segment
+	Duration time.Duration `json:"-"`
+
+	wr     io.WriteCloser
+	start  time.Time
+	closed bool
+}
+
+func NewConsumer(medias []*core.Media) *Consumer {
+	if medias == nil {
+		// default local medias
+		medias = []*core.Media{
+			{
+				Kind:      core.KindVideo,
+				Direction: core.DirectionSendonly,
+				Codecs: []*core.Codec{
+					{Name: core.CodecH264},
+					{Name: core.CodecH265},
+				},
+			},
+			{
+				Kind:      core.KindAudio,
+				Direction: core.DirectionSendonly,
+				Codecs: []*core.Codec{
+					{Name: core.CodecAAC},
+				},
+			},
+		}
+	}
+
+	return &Consumer{
+		Connection: core.Connection{
+			ID:         core.NewID(),
+			FormatName: "mp4",
+			Protocol:   "file",
+			Medias:     medias,
+		},
+		muxer: &mp4.Muxer{},
+	}
+}
+
+func (c *Consumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiver) error {
+	trackID := byte(len(c.Senders))
+
+	codec := track.Codec.Clone()
+	handler := core.NewSender(media, codec)
+
+	var video bool
+
+	switch track.Codec.Name {
+	case core.CodecH264:
+		handler.Handler = func(packet *rtp.Packet) {
+			c.write(trackID, packet, h264.IsKeyframe(packet.Payload), false)
+		}
+
+		if track.Codec.IsRTP() {
+			handler.Handler = h264.RTPDepay(track.Codec, handler.Handler)
+		} else {
+			handler.Handler = h264.RepairAVCC(track.Codec, handler.Handler)
+		}
+
+		video = true
+
+	case core.CodecH265:
+		handler.Handler = func(packet *rtp.Packet) {
+			c.write(trackID, packet, h265.IsKeyframe(packet.Payload), false)
+		}
+
+		if track.Codec.IsRTP() {
+			handler.Handler = h265.RTPDepay(track.Codec, handler.Handler)
+		} else {
+			handler.Handler = h265.RepairAVCC(track.Codec, handler.Handler)
+		}
+
+		video = true
+
+	default:
+		handler.Handler = func(packet *rtp.Packet) {
+			c.write(trackID, packet, false, true)
+		}
+
+		switch track.Codec.Name {
+		case core.CodecAAC:
+			if track.Codec.IsRTP() {
+				handler.Handler = aac.RTPDepay(handler.Handler)
+			}
+		case core.CodecOpus, core.CodecMP3: // no changes
+		case core.CodecPCMA, core.CodecPCMU, core.CodecPCM, core.CodecPCML:
+			codec.Name = core.CodecFLAC
+			if codec.Channels == 2 {
+				// hacky way for support two channels audio
+				codec.Channels = 1
+				codec.ClockRate *= 2
+			}
+			handler.Handler = pcm.FLACEncoder(track.Codec.Name, codec.ClockRate, handler.Handler)
+
+		default:
+			handler.Handler = nil
+		}
+	}
+
+	if handler.Handler == nil {
+		return errors.New("record: unsupported codec: " + track.Codec.String())
+	}
+
+	c.mu.Lock()
+	c.muxer.AddTrack(codec)
+	if video {
+		c.video = true
+	}
+	// init of the current segment doesn't have this track
+	c.closeSegment()
+	c.mu.Unlock()
+
+	handler.HandleRTP(track)
+	c.Senders = append(c.Senders, handler)
+
+	return nil
+}
+
+func (c *Consumer) Stop() error {
+	c.mu.Lock()
+	c.closeSegment()
+	c.closed = true
+	c.mu.Unlock()
+	return c.Connection.Stop()
+}
+
+func (c *Consumer) write(trackID byte, packet *rtp.Packet, keyframe, audio bool) {
+	// important to use Mutex because right fragment order
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	if audio {
+		// audio can start new segment only if there is no video
+		keyframe = !c.video
+	}
+
+	if keyframe && (c.wr == nil || c.Duration > 0 && time.Since(c.start) >= c.Duration) {
+		c.closeSegment()
+
+		if err := c.openSegment(); err != nil {
+			log.Error().Err(err).Caller().Send()
+			return
+		}
+	}
+
+	if c.wr == nil {
+		return // wait first keyframe
+	}
+
+	b := c.muxer.GetPayload(trackID, packet)
+	if n, err := c.wr.Write(b); err == nil {
+		c.Send += n
+	} else {
+		log.Error().Err(err).Caller().Send()
+		c.closeSegment()
+	}
+}
+
+func (c *Consumer) openSegment() error {
+	init, err := c.muxer.GetInit()
+	if err != nil {
+		return err
+	}
+
+	c.start = time.Now()
+
+	wr, err := c.Create(c.start)
+	if err != nil {
+		return err
+	}
+
+	if _, err = wr.Write(init); err != nil {
+		_ = wr.Close()
+		return err
+	}
+
+	// each segment should have its own timeline starting from zero
+	c.muxer.Reset()
+
+	c.wr = wr
+	return nil
+}
+
+func (c *Consumer) closeSegment() {
+	if c.wr != nil {
+		_ = c.wr.Close()
+		c.wr = nil
+	}
+}
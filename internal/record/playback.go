@@ -0,0 +1,380 @@
+package record
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/iso"
+)
+
+// Range - continuous period of recording
+type Range struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// maxGap - max pause between segments for join them into one range
+const maxGap = 5 * time.Second
+
+func GetRanges(segments []*Segment) (ranges []*Range) {
+	for _, s := range segments {
+		if n := len(ranges); n > 0 && s.Start.Sub(ranges[n-1].End) <= maxGap {
+			if s.End.After(ranges[n-1].End) {
+				ranges[n-1].End = s.End
+			}
+			continue
+		}
+		ranges = append(ranges, &Range{Start: s.Start, End: s.End})
+	}
+	return
+}
+
+// FilterSegments return segments that overlap with time range
+func FilterSegments(segments []*Segment, start, end time.Time) (items []*Segment) {
+	for _, s := range segments {
+		if s.End.Before(start) || s.Start.After(end) {
+			continue
+		}
+		items = append(items, s)
+	}
+	return
+}
+
+// ParseTime support RFC3339 and Unix time in seconds
+func ParseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("record: empty time")
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(i, 0).UTC(), nil
+	}
+	// plus sign in query may be decoded as space
+	s = strings.ReplaceAll(s, " ", "+")
+	return time.Parse(time.RFC3339, s)
+}
+
+type track struct {
+	timeScale uint32
+	video     bool
+}
+
+// fragment - moof+mdat pair from segment file
+type fragment struct {
+	b        []byte // moof+mdat
+	seq      []byte // link to mfhd sequence inside b
+	tfdt     []byte // link to tfdt time inside b (4 or 8 bytes)
+	trackID  uint32
+	dts      uint64
+	duration uint32
+	keyframe bool
+}
+
+// splitSegment return init (ftyp+moov), tracks info and all fragments from segment file
+func splitSegment(b []byte) (init []byte, tracks map[uint32]*track, frags []*fragment, err error) {
+	var moof []byte
+
+	for i := 0; i+8 <= len(b); {
+		size := int(binary.BigEndian.Uint32(b[i:]))
+		if size < 8 || i+size > len(b) {
+			break // last fragment may be incomplete
+		}
+
+		switch string(b[i+4 : i+8]) {
+		case iso.Ftyp, iso.Moov:
+			init = b[:i+size]
+		case iso.Moof:
+			moof = b[i : i+size]
+		case iso.Mdat:
+			if moof != nil {
+				if frag := parseFragment(b[i-len(moof) : i+size]); frag != nil {
+					frags = append(frags, frag)
+				}
+				moof = nil
+			}
+		}
+
+		i += size
+	}
+
+	if init == nil {
+		return nil, nil, nil, errors.New("record: can't find init")
+	}
+
+	if tracks, err = parseTracks(init); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return
+}
+
+func parseTracks(init []byte) (map[uint32]*track, error) {
+	atoms, err := iso.DecodeAtoms(init)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := map[uint32]*track{}
+
+	var tr *track
+	for _, atom := range atoms {
+		switch atom := atom.(type) {
+		case *iso.AtomTkhd:
+			tr = &track{}
+			tracks[atom.TrackID] = tr
+		case *iso.AtomMdhd:
+			if tr != nil {
+				tr.timeScale = atom.TimeScale
+			}
+		case *iso.Atom:
+			if atom.Name == iso.MoovTrakMdiaHdlr && tr != nil && len(atom.Data) >= 12 {
+				tr.video = string(atom.Data[8:12]) == "vide"
+			}
+		}
+	}
+
+	return tracks, nil
+}
+
+func parseFragment(b []byte) *fragment {
+	frag := &fragment{b: b}
+
+	moof := childAtom(b, iso.Moof)
+	mfhd := childAtom(moof, iso.MoofMfhd)
+	traf := childAtom(moof, iso.MoofTraf)
+	tfhd := childAtom(traf, iso.MoofTrafTfhd)
+	tfdt := childAtom(traf, iso.MoofTrafTfdt)
+	if len(mfhd) < 8 || len(tfhd) < 8 || len(tfdt) < 8 {
+		return nil
+	}
+
+	frag.seq = mfhd[4:8]
+
+	if tfdt[0] == 1 {
+		if len(tfdt) < 12 {
+			return nil
+		}
+		frag.tfdt = tfdt[4:12]
+		frag.dts = binary.BigEndian.Uint64(frag.tfdt)
+	} else {
+		frag.tfdt = tfdt[4:8]
+		frag.dts = uint64(binary.BigEndian.Uint32(frag.tfdt))
+	}
+
+	flags := binary.BigEndian.Uint32(tfhd) & 0xFFFFFF
+	frag.trackID = binary.BigEndian.Uint32(tfhd[4:])
+
+	// tfhd optional fields in strict order
+	p := tfhd[8:]
+	if flags&0x01 != 0 {
+		if len(p) < 8 {
+			return nil
+		}
+		p = p[8:] // base data offset
+	}
+	if flags&0x02 != 0 {
+		if len(p) < 4 {
+			return nil
+		}
+		p = p[4:] // sample description index
+	}
+	if flags&iso.TfhdDefaultSampleDuration != 0 && len(p) >= 4 {
+		frag.duration = binary.BigEndian.Uint32(p)
+		p = p[4:]
+	}
+	if flags&iso.TfhdDefaultSampleSize != 0 && len(p) >= 4 {
+		p = p[4:]
+	}
+	if flags&iso.TfhdDefaultSampleFlags != 0 && len(p) >= 4 {
+		frag.keyframe = binary.BigEndian.Uint32(p)&0x10000 == 0 // sample is sync
+	}
+
+	return frag
+}
+
+// childAtom return data of first child atom with name
+func childAtom(b []byte, name string) []byte {
+	for len(b) >= 8 {
+		size := binary.BigEndian.Uint32(b)
+		if size < 8 || int(size) > len(b) {
+			return nil
+		}
+		if string(b[4:8]) == name {
+			return b[8:size]
+		}
+		b = b[size:]
+	}
+	return nil
+}
+
+// Playback writes segments as single progressive fMP4 file, starting from
+// the last keyframe before start time and ending after end time.
+// Segments with different init (codecs) than the first one are skipped.
+func Playback(w io.Writer, segments []*Segment, start, end time.Time) error {
+	var init []byte
+	var tracks map[uint32]*track
+	var seq uint32
+
+	var offset float64     // output time in seconds for the current segment start
+	var buffer []*fragment // fragments from the last keyframe before start time
+
+	started := false
+
+	for _, segment := range segments {
+		data, err := os.ReadFile(segment.Path)
+		if err != nil {
+			return err
+		}
+
+		segInit, segTracks, frags, err := splitSegment(data)
+		if err != nil {
+			log.Warn().Err(err).Str("file", segment.Path).Send()
+			continue
+		}
+
+		if init == nil {
+			init = segInit
+			tracks = segTracks
+			if _, err = w.Write(init); err != nil {
+				return err
+			}
+		} else if !bytes.Equal(init, segInit) {
+			log.Debug().Str("file", segment.Path).Msg("[record] skip segment with other codecs")
+			continue
+		}
+
+		hasVideo := false
+		for _, tr := range tracks {
+			hasVideo = hasVideo || tr.video
+		}
+
+		var segEnd float64
+
+		for _, frag := range frags {
+			tr := tracks[frag.trackID]
+			if tr == nil || tr.timeScale == 0 {
+				continue
+			}
+
+			ts := float64(frag.dts) / float64(tr.timeScale)
+			t := segment.Start.Add(time.Duration(ts * float64(time.Second)))
+
+			if t.After(end) {
+				break
+			}
+
+			if !started {
+				if frag.keyframe && (tr.video || !hasVideo) {
+					buffer = buffer[:0]
+				} else if len(buffer) == 0 {
+					continue // wait keyframe
+				}
+
+				buffer = append(buffer, frag)
+
+				if t.Before(start) {
+					continue
+				}
+
+				// output timeline starts from the first buffered fragment
+				first := buffer[0]
+				offset = -float64(first.dts) / float64(tracks[first.trackID].timeScale)
+				started = true
+
+				for _, f := range buffer {
+					if segEnd, err = writeFragment(w, f, tracks, offset, &seq, segEnd); err != nil {
+						return err
+					}
+				}
+				buffer = nil
+				continue
+			}
+
+			if segEnd, err = writeFragment(w, frag, tracks, offset, &seq, segEnd); err != nil {
+				return err
+			}
+		}
+
+		if started {
+			// next segment continues after the last fragment of this one
+			offset = segEnd
+		} else {
+			// the buffer can't be continued in the next segment with new timeline
+			buffer = nil
+		}
+	}
+
+	if init == nil {
+		return errors.New("record: no segments")
+	}
+
+	return nil
+}
+
+func writeFragment(w io.Writer, frag *fragment, tracks map[uint32]*track, offset float64, seq *uint32, end float64) (float64, error) {
+	timeScale := float64(tracks[frag.trackID].timeScale)
+
+	dts := offset + float64(frag.dts)/timeScale
+	if dts < 0 {
+		dts = 0
+	}
+
+	*seq++
+	binary.BigEndian.PutUint32(frag.seq, *seq)
+
+	if v := uint64(dts * timeScale); len(frag.tfdt) == 8 {
+		binary.BigEndian.PutUint64(frag.tfdt, v)
+	} else {
+		binary.BigEndian.PutUint32(frag.tfdt, uint32(v))
+	}
+
+	if _, err := w.Write(frag.b); err != nil {
+		return end, err
+	}
+
+	if fragEnd := dts + float64(frag.duration)/timeScale; fragEnd > end {
+		end = fragEnd
+	}
+
+	return end, nil
+}
+
+// Playlist return HLS VOD playlist for segments. Every segment has its own
+// init and timeline, so they are separated with discontinuity tag.
+func Playlist(src string, segments []*Segment, start time.Time) []byte {
+	src = url.QueryEscape(src)
+
+	var duration float64
+	for _, s := range segments {
+		duration = max(duration, s.End.Sub(s.Start).Seconds())
+	}
+
+	b := []byte("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-PLAYLIST-TYPE:VOD\n#EXT-X-MEDIA-SEQUENCE:0\n")
+	b = append(b, "#EXT-X-TARGETDURATION:"+strconv.Itoa(int(math.Ceil(duration)))+"\n"...)
+
+	if offset := start.Sub(segments[0].Start).Seconds(); offset > 0 {
+		b = append(b, "#EXT-X-START:TIME-OFFSET="+strconv.FormatFloat(offset, 'f', 3, 64)+"\n"...)
+	}
+
+	for i, s := range segments {
+		id := strings.TrimSuffix(filepath.Base(s.Path), ".mp4")
+		if i > 0 {
+			b = append(b, "#EXT-X-DISCONTINUITY\n"...)
+		}
+		b = append(b, "#EXT-X-PROGRAM-DATE-TIME:"+s.Start.Format("2006-01-02T15:04:05.000Z07:00")+"\n"...)
+		b = append(b, `#EXT-X-MAP:URI="init.mp4?src=`+src+"&id="+id+`"`+"\n"...)
+		b = append(b, "#EXTINF:"+strconv.FormatFloat(s.End.Sub(s.Start).Seconds(), 'f', 3, 64)+",\n"...)
+		b = append(b, "segment.m4s?src="+src+"&id="+id+"\n"...)
+	}
+
+	b = append(b, "#EXT-X-ENDLIST\n"...)
+	return b
+}
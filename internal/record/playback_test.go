@@ -0,0 +1,97 @@
+package record
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayback(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 10, 18, 14, 32, 0, 0, time.UTC)
+
+	// segment per GOP, every GOP is one second
+	var n int
+	cons := NewConsumer(nil)
+	cons.Duration = time.Nanosecond
+	cons.Create = func(time.Time) (io.WriteCloser, error) {
+		start := base.Add(time.Duration(n) * time.Second)
+		n++
+		return os.Create(filepath.Join(dir, start.Format(TimeLayout)+".mp4"))
+	}
+	cons.muxer.AddTrack(&core.Codec{Name: core.CodecH264, ClockRate: 90000})
+
+	for i := 0; i < 75; i++ {
+		payload := []byte{0, 0, 0, 2, 0x41, 0} // P-frame
+		if i%25 == 0 {
+			payload[4] = 0x65 // I-frame
+		}
+		packet := &rtp.Packet{
+			Header:  rtp.Header{Timestamp: uint32(1e6 + i*3600)},
+			Payload: payload,
+		}
+		cons.write(0, packet, i%25 == 0, false)
+	}
+	require.Nil(t, cons.Stop())
+
+	segments := ReadSegments(dir)
+	require.Len(t, segments, 3)
+
+	buf := bytes.NewBuffer(nil)
+	err := Playback(buf, segments, base.Add(1500*time.Millisecond), base.Add(time.Hour))
+	require.Nil(t, err)
+
+	_, _, frags, err := splitSegment(buf.Bytes())
+	require.Nil(t, err)
+
+	// playback starts from keyframe of the second segment
+	require.Len(t, frags, 50)
+	require.True(t, frags[0].keyframe)
+	require.True(t, frags[25].keyframe)
+
+	// single timeline for all segments
+	require.Equal(t, uint64(0), frags[0].dts)
+	for i := 1; i < len(frags); i++ {
+		require.Greater(t, frags[i].dts, frags[i-1].dts)
+		require.Equal(t, uint32(i+1), binary.BigEndian.Uint32(frags[i].seq))
+	}
+}
+
+func TestGetRanges(t *testing.T) {
+	base := time.Date(2026, 10, 18, 14, 32, 0, 0, time.UTC)
+	segments := []*Segment{
+		{Start: base, End: base.Add(time.Minute)},
+		{Start: base.Add(time.Minute), End: base.Add(2 * time.Minute)},
+		{Start: base.Add(time.Hour), End: base.Add(time.Hour + time.Minute)},
+	}
+
+	ranges := GetRanges(segments)
+	require.Len(t, ranges, 2)
+	require.Equal(t, base.Add(2*time.Minute), ranges[0].End)
+	require.Equal(t, base.Add(time.Hour), ranges[1].Start)
+}
+
+func TestParseFragmentTruncated(t *testing.T) {
+	atom := func(name string, data ...[]byte) []byte {
+		b := binary.BigEndian.AppendUint32(nil, 0)
+		b = append(b, name...)
+		for _, d := range data {
+			b = append(b, d...)
+		}
+		binary.BigEndian.PutUint32(b, uint32(len(b)))
+		return b
+	}
+
+	// tfhd with base data offset flag, but without the offset
+	tfhd := atom("tfhd", []byte{0, 0, 0, 1, 0, 0, 0, 1})
+	moof := atom("moof", atom("mfhd", make([]byte, 8)), atom("traf", tfhd, atom("tfdt", make([]byte, 8))))
+	require.Nil(t, parseFragment(moof))
+}
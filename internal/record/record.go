@@ -0,0 +1,229 @@
+package record
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/rs/zerolog"
+)
+
+func Init() {
+	var cfg struct {
+		Mod struct {
+			Path            string            `yaml:"path"`
+			SegmentDuration time.Duration     `yaml:"segment_duration"`
+			MaxAge          time.Duration     `yaml:"max_age"`
+			MaxSize         string            `yaml:"max_size"`
+			Streams         map[string]string `yaml:"streams"`
+			Clips           struct {
+				Path   string        `yaml:"path"`
+				Pre    time.Duration `yaml:"pre"`
+				Post   time.Duration `yaml:"post"`
+				Motion bool          `yaml:"motion"`
+			} `yaml:"clips"`
+		} `yaml:"record"`
+	}
+
+	// default config
+	cfg.Mod.Path = "record"
+	cfg.Mod.SegmentDuration = time.Minute
+	cfg.Mod.Clips.Path = "clips"
+	cfg.Mod.Clips.Pre = 5 * time.Second
+	cfg.Mod.Clips.Post = 20 * time.Second
+
+	app.LoadConfig(&cfg)
+
+	log = app.GetLogger("record")
+
+	storagePath = cfg.Mod.Path
+	segmentDuration = cfg.Mod.SegmentDuration
+	maxAge = cfg.Mod.MaxAge
+
+	clipsPath = cfg.Mod.Clips.Path
+	clipsPre = cfg.Mod.Clips.Pre
+	clipsPost = cfg.Mod.Clips.Post
+	clipsMotion = cfg.Mod.Clips.Motion
+
+	if cfg.Mod.MaxSize != "" {
+		var err error
+		if maxSize, err = ParseSize(cfg.Mod.MaxSize); err != nil {
+			log.Error().Err(err).Caller().Send()
+		}
+	}
+
+	api.HandleFunc("api/record", apiRecord)
+	api.HandleFunc("api/record/list", apiList)
+	api.HandleFunc("api/record/playback", apiPlayback)
+	api.HandleFunc("api/record/playback.m3u8", apiPlaylist)
+	api.HandleFunc("api/record/init.mp4", apiInit)
+	api.HandleFunc("api/record/segment.m4s", apiSegment)
+	api.HandleFunc("api/clip", apiClip)
+
+	if maxAge > 0 || maxSize > 0 {
+		go retention()
+	}
+
+	if cfg.Mod.Streams == nil {
+		return
+	}
+
+	go func() {
+		streams.WaitReady()
+
+		for name, rawQuery := range cfg.Mod.Streams {
+			if err := AddRecord(name, rawQuery); err != nil {
+				log.Error().Err(err).Caller().Send()
+			}
+		}
+	}()
+}
+
+var log zerolog.Logger
+
+var (
+	storagePath     string
+	segmentDuration time.Duration
+	maxAge          time.Duration
+	maxSize         int64
+)
+
+type Record struct {
+	stream *streams.Stream
+	Cons   *Consumer `json:"consumer"`
+	Query  string    `json:"query"`
+	Path   string    `json:"path"`
+
+	done chan struct{}
+}
+
+var records = map[string]*Record{}
+var recordsMu sync.Mutex
+
+const retryTimeout = 30 * time.Second
+
+func AddRecord(name, rawQuery string) error {
+	if rawQuery == "" {
+		rawQuery = "video&audio"
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return err
+	}
+
+	recordsMu.Lock()
+	defer recordsMu.Unlock()
+
+	if rec := records[name]; rec != nil {
+		rec.close()
+	}
+
+	stream := streams.Get(name)
+	if stream == nil {
+		return fmt.Errorf("record: stream not found: %s", name)
+	}
+
+	rec := &Record{
+		stream: stream,
+		Query:  rawQuery,
+		Path:   StreamPath(name),
+		done:   make(chan struct{}),
+	}
+
+	rec.Cons = NewConsumer(core.ParseQuery(query))
+	rec.Cons.Duration = segmentDuration
+	rec.Cons.Create = func(start time.Time) (io.WriteCloser, error) {
+		if err := os.MkdirAll(rec.Path, 0755); err != nil {
+			return nil, err
+		}
+		filename := filepath.Join(rec.Path, start.UTC().Format(TimeLayout)+".mp4")
+		log.Debug().Str("file", filename).Msg("[record] new segment")
+		// never overwrite the existing segment
+		return os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	}
+
+	go rec.run(name)
+
+	records[name] = rec
+	return nil
+}
+
+func DelRecord(name string) error {
+	recordsMu.Lock()
+	defer recordsMu.Unlock()
+
+	if rec := records[name]; rec != nil {
+		rec.close()
+		delete(records, name)
+		return nil
+	}
+
+	return fmt.Errorf("record: record not found: %s", name)
+}
+
+func GetRecords() map[string]*Record {
+	recordsMu.Lock()
+	defer recordsMu.Unlock()
+	return maps.Clone(records)
+}
+
+// TimeLayout - segment filename format, always in UTC
+const TimeLayout = "20060102T150405.000Z"
+
+// parseLayout - also parses filenames without milliseconds from older versions
+const parseLayout = "20060102T150405Z"
+
+// StreamPath - folder with segments for stream name
+func StreamPath(name string) string {
+	return filepath.Join(storagePath, escapeName(name))
+}
+
+// escapeName - safe folder name for stream name
+func escapeName(name string) string {
+	name = url.PathEscape(name)
+	if name == "." || name == ".." {
+		name = strings.ReplaceAll(name, ".", "%2E") // protect from path traversal
+	}
+	return name
+}
+
+// run adds consumer to the stream and retries if the source is not available
+func (r *Record) run(name string) {
+	for {
+		err := r.stream.AddConsumer(r.Cons)
+		if err == nil {
+			select {
+			case <-r.done:
+				// record was deleted while adding consumer
+				r.stream.RemoveConsumer(r.Cons)
+			default:
+				log.Debug().Str("stream", name).Msg("[record] start")
+			}
+			return
+		}
+
+		log.Warn().Err(err).Str("stream", name).Msg("[record] can't start")
+
+		select {
+		case <-r.done:
+			return
+		case <-time.After(retryTimeout):
+		}
+	}
+}
+
+func (r *Record) close() {
+	close(r.done)
+	r.stream.RemoveConsumer(r.Cons)
+}
@@ -0,0 +1,158 @@
+package record
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Segment struct {
+	Path  string
+	Start time.Time
+	End   time.Time
+	Size  int64
+}
+
+// ReadSegments return all segments from folder sorted by start time
+func ReadSegments(dir string) []*Segment {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var segments []*Segment
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".mp4")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		start, err := time.Parse(parseLayout, name)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		segments = append(segments, &Segment{
+			Path:  filepath.Join(dir, entry.Name()),
+			Start: start,
+			End:   info.ModTime().UTC(),
+			Size:  info.Size(),
+		})
+	}
+
+	slices.SortFunc(segments, func(a, b *Segment) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	return segments
+}
+
+func retention() {
+	for range time.Tick(time.Minute) {
+		cleanup()
+	}
+}
+
+func cleanup() {
+	entries, err := os.ReadDir(storagePath)
+	if err != nil {
+		return
+	}
+
+	var segments []*Segment
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		items := ReadSegments(filepath.Join(storagePath, entry.Name()))
+		if len(items) == 0 {
+			continue
+		}
+		// skip last segment, because it may be still recording
+		segments = append(segments, items[:len(items)-1]...)
+	}
+
+	// clips use the same retention
+	if clipsPath != storagePath {
+		segments = append(segments, clipSegments()...)
+	}
+
+	if maxAge > 0 {
+		deadline := time.Now().Add(-maxAge)
+		segments = slices.DeleteFunc(segments, func(s *Segment) bool {
+			if s.End.After(deadline) {
+				return false
+			}
+			remove(s)
+			return true
+		})
+	}
+
+	if maxSize > 0 {
+		slices.SortFunc(segments, func(a, b *Segment) int {
+			return a.Start.Compare(b.Start)
+		})
+
+		var total int64
+		for _, s := range segments {
+			total += s.Size
+		}
+
+		for _, s := range segments {
+			if total <= maxSize {
+				break
+			}
+			remove(s)
+			total -= s.Size
+		}
+	}
+}
+
+func remove(s *Segment) {
+	log.Debug().Str("file", s.Path).Msg("[record] remove segment")
+	if err := os.Remove(s.Path); err != nil {
+		log.Warn().Err(err).Caller().Send()
+	}
+}
+
+// ParseSize support values like 500MB, 10GB, 1TB or plain bytes
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "B")
+
+	var mul int64 = 1
+
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'K':
+			mul = 1 << 10
+		case 'M':
+			mul = 1 << 20
+		case 'G':
+			mul = 1 << 30
+		case 'T':
+			mul = 1 << 40
+		}
+		if mul > 1 {
+			s = s[:n-1]
+		}
+	}
+
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || f < 0 {
+		return 0, errors.New("record: wrong size: " + s)
+	}
+
+	return int64(f * float64(mul)), nil
+}
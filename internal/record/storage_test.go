@@ -0,0 +1,89 @@
+package record
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"1024":  1024,
+		"10K":   10 << 10,
+		"500MB": 500 << 20,
+		"1.5GB": 3 << 29,
+		"2tb":   2 << 40,
+	} {
+		n, err := ParseSize(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, n, s)
+	}
+
+	_, err := ParseSize("abc")
+	require.Error(t, err)
+}
+
+func TestReadSegments(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{
+		"20261018T143200Z.mp4",
+		"20261018T143100Z.mp4",
+		"20261018T143200.500Z.mp4",
+		"wrong.mp4",
+		"20261018T143000Z.txt",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{0}, 0644))
+	}
+
+	segments := ReadSegments(dir)
+	require.Len(t, segments, 3)
+	require.Equal(t, time.Date(2026, 10, 18, 14, 31, 0, 0, time.UTC), segments[0].Start)
+	require.Equal(t, time.Date(2026, 10, 18, 14, 32, 0, 0, time.UTC), segments[1].Start)
+	require.Equal(t, int64(1), segments[1].Size)
+
+	// segments restarted within the same second
+	require.Equal(t, time.Date(2026, 10, 18, 14, 32, 0, 500e6, time.UTC), segments[2].Start)
+	require.Equal(t, "20261018T143200.500Z", segments[2].Start.Format(TimeLayout))
+}
+
+func TestClipSegments(t *testing.T) {
+	clipsPath = t.TempDir()
+	dir := filepath.Join(clipsPath, "camera1")
+	require.NoError(t, os.Mkdir(dir, 0755))
+
+	for _, name := range []string{"20261018T143000Z.mp4", "20261018T143100Z.mp4"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{0}, 0644))
+	}
+
+	// active clip is not removed by retention
+	clips["camera1"] = &Clip{Path: filepath.Join(dir, "20261018T143100Z.mp4")}
+	defer delete(clips, "camera1")
+
+	segments := clipSegments()
+	require.Len(t, segments, 1)
+	require.Equal(t, filepath.Join(dir, "20261018T143000Z.mp4"), segments[0].Path)
+}
+
+func TestClipJSON(t *testing.T) {
+	start := time.Date(2026, 10, 18, 14, 31, 0, 0, time.UTC)
+	clip := &Clip{Stream: "camera1", Start: start, End: start}
+
+	// clip can be extended by the motion event while the API writes the response
+	done := make(chan struct{})
+	go func() {
+		clipsMu.Lock()
+		clip.End = start.Add(time.Minute)
+		clipsMu.Unlock()
+		close(done)
+	}()
+
+	b, err := json.Marshal(clip)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"stream":"camera1","path":"","start":"2026-10-18T14:31:00Z"`)
+	<-done
+}
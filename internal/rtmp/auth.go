@@ -0,0 +1,90 @@
+package rtmp
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/AlexxIT/go2rtc/internal/users"
+	"github.com/AlexxIT/go2rtc/pkg/rtmp"
+)
+
+var publishKeys map[string]string // stream name or pattern => publish key
+var autoCreate bool
+
+// authorize checks the publish key for the stream or user permissions.
+// Credentials are taken from the app or stream key query: rtmp://host/stream?key=... or ?user=...&pass=...
+// The stream key without query is also used as the publish key: rtmp://host/stream/key
+func authorize(conn *rtmp.Conn, name, rawQuery string, remoteAddr net.Addr) (authenticated bool, err error) {
+	query := parseQuery(rawQuery)
+
+	streamKey, rawQuery, _ := strings.Cut(conn.Stream, "?")
+	for k, v := range parseQuery(rawQuery) {
+		if !query.Has(k) {
+			query[k] = v
+		}
+	}
+
+	if conn.Intent == rtmp.CommandPublish {
+		if secrets := publishSecrets(name); secrets != nil {
+			key := query.Get("key")
+			if key == "" && streamKey != name {
+				key = streamKey
+			}
+			for _, secret := range secrets {
+				if subtle.ConstantTimeCompare([]byte(key), []byte(secret)) == 1 {
+					return true, nil
+				}
+			}
+			if key == "" {
+				return false, errors.New("empty publish key")
+			}
+			return false, errors.New("wrong publish key")
+		}
+	}
+
+	if !users.Enabled() {
+		return false, nil
+	}
+
+	// skip check auth for localhost
+	if addr, ok := remoteAddr.(*net.TCPAddr); ok && addr.IP.IsLoopback() {
+		return false, nil
+	}
+
+	user := users.Auth(query.Get("user"), query.Get("pass"))
+	if user == nil {
+		return false, errors.New("failed authentication")
+	}
+
+	perm := users.PermView
+	if conn.Intent == rtmp.CommandPublish {
+		perm = users.PermPublish
+	}
+
+	if !user.Allow(perm, name) {
+		return false, errors.New("forbidden for user " + user.Name())
+	}
+
+	return true, nil
+}
+
+// publishSecrets return keys for the stream name, nil if publishing without key is allowed
+func publishSecrets(name string) (secrets []string) {
+	for pattern, secret := range publishKeys {
+		if pattern == name {
+			secrets = append(secrets, secret)
+		} else if ok, _ := path.Match(pattern, name); ok {
+			secrets = append(secrets, secret)
+		}
+	}
+	return
+}
+
+func parseQuery(rawQuery string) url.Values {
+	query, _ := url.ParseQuery(rawQuery)
+	return query
+}
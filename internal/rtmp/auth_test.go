@@ -0,0 +1,48 @@
+package rtmp
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/AlexxIT/go2rtc/pkg/rtmp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	publishKeys = map[string]string{"drone1": "secret1", "site_*": "secret2"}
+	defer func() { publishKeys = nil }()
+
+	remote := &net.TCPAddr{IP: net.IPv4(192, 168, 1, 123)}
+
+	tests := []struct {
+		app, stream string
+		ok          bool
+	}{
+		{"drone1", "secret1", true},                        // OBS: server rtmp://host/drone1, key secret1
+		{"drone1?key=secret1", "", true},                   // FFmpeg: rtmp://host/drone1?key=secret1
+		{"drone1?key=secret1", "drone1?key=secret1", true}, // OBS: server rtmp://host/, key drone1?key=secret1
+		{"drone1", "drone1", false},
+		{"drone1", "secret2", false},
+		{"site_cam1", "secret2", true},
+		{"site_cam1", "secret1", false},
+		{"other", "", true}, // without key
+	}
+
+	for _, test := range tests {
+		conn := &rtmp.Conn{App: test.app, Stream: test.stream, Intent: rtmp.CommandPublish}
+		name, rawQuery, _ := strings.Cut(conn.App, "?")
+		authenticated, err := authorize(conn, name, rawQuery, remote)
+		if test.ok {
+			require.Nil(t, err, test)
+			require.Equal(t, name != "other", authenticated, test)
+		} else {
+			require.NotNil(t, err, test)
+		}
+	}
+
+	// keys are checked only for publishing
+	conn := &rtmp.Conn{App: "drone1", Intent: rtmp.CommandPlay}
+	_, err := authorize(conn, "drone1", "", remote)
+	require.Nil(t, err)
+}
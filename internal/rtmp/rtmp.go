@@ -1,10 +1,12 @@
 package rtmp
 
 import (
+	"crypto/tls"
 	"errors"
 	"io"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/app"
@@ -12,20 +14,33 @@ import (
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/flv"
 	"github.com/AlexxIT/go2rtc/pkg/rtmp"
+	"github.com/AlexxIT/go2rtc/pkg/tcp"
 	"github.com/rs/zerolog"
 )
 
 func Init() {
 	var conf struct {
 		Mod struct {
-			Listen string `yaml:"listen" json:"listen"`
+			Listen     string            `yaml:"listen" json:"listen"`
+			TLSListen  string            `yaml:"tls_listen" json:"tls_listen,omitempty"`
+			TLSCert    string            `yaml:"tls_cert" json:"-"`
+			TLSKey     string            `yaml:"tls_key" json:"-"`
+			Publish    map[string]string `yaml:"publish" json:"-"`
+			AutoCreate bool              `yaml:"auto_create" json:"auto_create"`
 		} `yaml:"rtmp"`
+		API struct {
+			TLSCert string `yaml:"tls_cert"`
+			TLSKey  string `yaml:"tls_key"`
+		} `yaml:"api"`
 	}
 
 	app.LoadConfig(&conf)
 
 	log = app.GetLogger("rtmp")
 
+	publishKeys = conf.Mod.Publish
+	autoCreate = conf.Mod.AutoCreate
+
 	streams.HandleFunc("rtmp", streamsHandle)
 	streams.HandleFunc("rtmps", streamsHandle)
 	streams.HandleFunc("rtmpx", streamsHandle)
@@ -36,33 +51,58 @@ func Init() {
 	streams.HandleConsumerFunc("rtmps", streamsConsumerHandle)
 	streams.HandleConsumerFunc("rtmpx", streamsConsumerHandle)
 
-	address := conf.Mod.Listen
-	if address == "" {
-		return
-	}
+	if address := conf.Mod.Listen; address != "" {
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Error().Err(err).Caller().Send()
+		} else {
+			log.Info().Str("addr", address).Msg("[rtmp] listen")
 
-	ln, err := net.Listen("tcp", address)
-	if err != nil {
-		log.Error().Err(err).Caller().Send()
-		return
+			go serve(ln)
+		}
 	}
 
-	log.Info().Str("addr", address).Msg("[rtmp] listen")
+	if address := conf.Mod.TLSListen; address != "" {
+		// use the API certificate by default
+		if conf.Mod.TLSCert == "" {
+			conf.Mod.TLSCert = conf.API.TLSCert
+			conf.Mod.TLSKey = conf.API.TLSKey
+		}
 
-	go func() {
-		for {
-			conn, err := ln.Accept()
-			if err != nil {
-				return
-			}
+		cert, err := tcp.LoadCertificate(conf.Mod.TLSCert, conf.Mod.TLSKey)
+		if err != nil {
+			log.Error().Err(err).Msg("[rtmp] tls cert")
+			return
+		}
 
-			go func() {
-				if err = tcpHandle(conn); err != nil {
-					log.Error().Err(err).Caller().Send()
-				}
-			}()
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Error().Err(err).Msg("[rtmp] tls listen")
+			return
 		}
-	}()
+
+		log.Info().Str("addr", address).Msg("[rtmp] tls listen")
+
+		ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+		go serve(ln)
+	}
+}
+
+func serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			if err = tcpHandle(conn); err != nil {
+				log.Error().Err(err).Caller().Send()
+			}
+			_ = conn.Close()
+		}()
+	}
 }
 
 func tcpHandle(netConn net.Conn) error {
@@ -75,11 +115,21 @@ func tcpHandle(netConn net.Conn) error {
 		return err
 	}
 
+	name, rawQuery, _ := strings.Cut(rtmpConn.App, "?")
+
+	authenticated, err := authorize(rtmpConn, name, rawQuery, netConn.RemoteAddr())
+	if err != nil {
+		log.Warn().Str("remote_addr", netConn.RemoteAddr().String()).Str("stream", name).
+			Str("intent", rtmpConn.Intent).Msgf("[rtmp] rejected: %s", err)
+		_ = rtmpConn.WriteReject(err.Error())
+		return nil
+	}
+
 	switch rtmpConn.Intent {
 	case rtmp.CommandPlay:
-		stream := streams.Get(rtmpConn.App)
+		stream := streams.Get(name)
 		if stream == nil {
-			return errors.New("stream not found: " + rtmpConn.App)
+			return errors.New("stream not found: " + name)
 		}
 
 		cons := flv.NewConsumer()
@@ -98,11 +148,15 @@ func tcpHandle(netConn net.Conn) error {
 		return nil
 
 	case rtmp.CommandPublish:
-		stream := streams.Get(rtmpConn.App)
+		// temporary stream only for the publishing time, it is deleted
+		// after the last publisher is finished
+		stream := streams.Acquire(name, autoCreate && authenticated)
 		if stream == nil {
-			return errors.New("stream not found: " + rtmpConn.App)
+			return errors.New("stream not found: " + name)
 		}
 
+		defer streams.Release(name, stream)
+
 		if err = rtmpConn.WriteStart(); err != nil {
 			return err
 		}
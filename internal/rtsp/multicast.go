@@ -0,0 +1,121 @@
+package rtsp
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/rtsp"
+)
+
+// multicastGroup - one shared consumer for all multicast clients of a stream with the same query
+type multicastGroup struct {
+	addr    *net.UDPAddr
+	cons    *rtsp.Conn
+	stream  *streams.Stream
+	clients int
+}
+
+var (
+	multicastAddr *net.UDPAddr // first group address and port
+	multicastTTL  int
+	multicasts    = map[string]*multicastGroup{}
+	multicastMu   sync.Mutex
+)
+
+func initMulticast(address string, ttl int) error {
+	addr, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return err
+	}
+	if !addr.IP.IsMulticast() {
+		return errors.New("rtsp: wrong multicast group: " + address)
+	}
+	if addr.Port == 0 {
+		addr.Port = 5000
+	}
+	multicastAddr = addr
+	multicastTTL = ttl
+	return nil
+}
+
+// multicastJoin return the group address for the stream and start the shared consumer for the first client
+func multicastJoin(key string, stream *streams.Stream, medias []*core.Media) (*net.UDPAddr, error) {
+	multicastMu.Lock()
+	defer multicastMu.Unlock()
+
+	if group := multicasts[key]; group != nil {
+		group.clients++
+		return group.addr, nil
+	}
+
+	addr := multicastNextAddr()
+
+	// copy medias, because server connection changes them on SETUP
+	clones := make([]*core.Media, len(medias))
+	for i, media := range medias {
+		clones[i] = media.Clone()
+	}
+
+	cons, err := rtsp.NewMulticast(clones, addr, multicastTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = stream.AddConsumer(cons); err != nil {
+		_ = cons.Stop()
+		return nil, err
+	}
+
+	log.Debug().Str("group", addr.String()).Msg("[rtsp] start multicast")
+
+	multicasts[key] = &multicastGroup{addr: addr, cons: cons, stream: stream, clients: 1}
+
+	return addr, nil
+}
+
+// multicastLeave stop the shared consumer after the last client
+func multicastLeave(key string) {
+	multicastMu.Lock()
+	defer multicastMu.Unlock()
+
+	group := multicasts[key]
+	if group == nil {
+		return
+	}
+
+	if group.clients--; group.clients > 0 {
+		return
+	}
+
+	log.Debug().Str("group", group.addr.String()).Msg("[rtsp] stop multicast")
+
+	delete(multicasts, key)
+
+	group.stream.RemoveConsumer(group.cons)
+	_ = group.cons.Stop()
+}
+
+// multicastNextAddr return first unused group IP starting from config address
+func multicastNextAddr() *net.UDPAddr {
+	base := binary.BigEndian.Uint32(multicastAddr.IP.To4())
+
+	for i := uint32(0); ; i++ {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, base+i)
+
+		var used bool
+		for _, group := range multicasts {
+			if group.addr.IP.Equal(ip) {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return &net.UDPAddr{IP: ip, Port: multicastAddr.Port}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package rtsp
 
 import (
+	"crypto/tls"
 	"errors"
 	"io"
 	"net"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/AlexxIT/go2rtc/internal/app"
 	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/internal/users"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/rtsp"
 	"github.com/AlexxIT/go2rtc/pkg/tcp"
@@ -19,16 +21,28 @@ func Init() {
 	var conf struct {
 		Mod struct {
 			Listen       string `yaml:"listen" json:"listen"`
+			TLSListen    string `yaml:"tls_listen" json:"tls_listen,omitempty"`
+			TLSCert      string `yaml:"tls_cert" json:"-"`
+			TLSKey       string `yaml:"tls_key" json:"-"`
 			Username     string `yaml:"username" json:"-"`
 			Password     string `yaml:"password" json:"-"`
 			DefaultQuery string `yaml:"default_query" json:"default_query"`
 			PacketSize   uint16 `yaml:"pkt_size" json:"pkt_size,omitempty"`
+			Multicast    struct {
+				Group string `yaml:"group" json:"group"`
+				TTL   int    `yaml:"ttl" json:"ttl"`
+			} `yaml:"multicast" json:"multicast"`
 		} `yaml:"rtsp"`
+		API struct {
+			TLSCert string `yaml:"tls_cert"`
+			TLSKey  string `yaml:"tls_key"`
+		} `yaml:"api"`
 	}
 
 	// default config
 	conf.Mod.Listen = ":8554"
 	conf.Mod.DefaultQuery = "video&audio"
+	conf.Mod.Multicast.TTL = 1
 
 	app.LoadConfig(&conf)
 	app.Info["rtsp"] = conf.Mod
@@ -40,42 +54,89 @@ func Init() {
 	streams.HandleFunc("rtsps", rtspHandler)
 	streams.HandleFunc("rtspx", rtspHandler)
 
+	// RTSP push support (publish)
+	streams.HandleConsumerFunc("rtsp", streamsConsumerHandle)
+	streams.HandleConsumerFunc("rtsps", streamsConsumerHandle)
+	streams.HandleConsumerFunc("rtspx", streamsConsumerHandle)
+
 	// RTSP server support
-	address := conf.Mod.Listen
-	if address == "" {
-		return
+	if query, err := url.ParseQuery(conf.Mod.DefaultQuery); err == nil {
+		defaultMedias = ParseQuery(query)
 	}
 
-	ln, err := net.Listen("tcp", address)
-	if err != nil {
-		log.Error().Err(err).Msg("[rtsp] listen")
-		return
+	if conf.Mod.Multicast.Group != "" {
+		if err := initMulticast(conf.Mod.Multicast.Group, conf.Mod.Multicast.TTL); err != nil {
+			log.Error().Err(err).Msg("[rtsp] multicast")
+		}
 	}
 
-	_, Port, _ = net.SplitHostPort(address)
+	if address := conf.Mod.Listen; address != "" {
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Error().Err(err).Msg("[rtsp] listen")
+		} else {
+			_, Port, _ = net.SplitHostPort(address)
 
-	log.Info().Str("addr", address).Msg("[rtsp] listen")
+			log.Info().Str("addr", address).Msg("[rtsp] listen")
 
-	if query, err := url.ParseQuery(conf.Mod.DefaultQuery); err == nil {
-		defaultMedias = ParseQuery(query)
+			go serve(ln, conf.Mod.Username, conf.Mod.Password, conf.Mod.PacketSize)
+		}
 	}
 
-	go func() {
-		for {
-			conn, err := ln.Accept()
-			if err != nil {
-				return
-			}
+	if address := conf.Mod.TLSListen; address != "" {
+		// use the API certificate by default
+		if conf.Mod.TLSCert == "" {
+			conf.Mod.TLSCert = conf.API.TLSCert
+			conf.Mod.TLSKey = conf.API.TLSKey
+		}
+
+		cert, err := tcp.LoadCertificate(conf.Mod.TLSCert, conf.Mod.TLSKey)
+		if err != nil {
+			log.Error().Err(err).Msg("[rtsp] tls cert")
+			return
+		}
 
-			c := rtsp.NewServer(conn)
-			c.PacketSize = conf.Mod.PacketSize
-			// skip check auth for localhost
-			if conf.Mod.Username != "" && !conn.RemoteAddr().(*net.TCPAddr).IP.IsLoopback() {
-				c.Auth(conf.Mod.Username, conf.Mod.Password)
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			log.Error().Err(err).Msg("[rtsp] tls listen")
+			return
+		}
+
+		_, TLSPort, _ = net.SplitHostPort(address)
+
+		log.Info().Str("addr", address).Msg("[rtsp] tls listen")
+
+		ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+		go serve(ln, conf.Mod.Username, conf.Mod.Password, conf.Mod.PacketSize)
+	}
+}
+
+func serve(ln net.Listener, username, password string, packetSize uint16) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		c := rtsp.NewServer(conn)
+		c.PacketSize = packetSize
+		// skip check auth for localhost
+		if !conn.RemoteAddr().(*net.TCPAddr).IP.IsLoopback() {
+			if users.Enabled() {
+				c.AuthFunc(func(user, pass string) bool {
+					// global credentials have full access
+					if username != "" && user == username && pass == password {
+						return true
+					}
+					return users.Auth(user, pass) != nil
+				})
+			} else if username != "" {
+				c.Auth(username, password)
 			}
-			go tcpHandler(c)
 		}
-	}()
+		go tcpHandler(c)
+	}
 }
 
 type Handler func(conn *rtsp.Conn) bool
@@ -85,6 +146,7 @@ func HandleFunc(handler Handler) {
 }
 
 var Port string
+var TLSPort string
 
 // internal
 
@@ -150,7 +212,14 @@ func tcpHandler(conn *rtsp.Conn) {
 	trace := log.Trace().Enabled()
 	level := zerolog.WarnLevel
 
+	// ONVIF clients request two-way audio with the Require header
+	var onvifBackchannel bool
+
 	conn.Listen(func(msg any) {
+		if req, ok := msg.(*tcp.Request); ok && req.Method == rtsp.MethodDescribe {
+			onvifBackchannel = strings.Contains(req.Header.Get("Require"), "www.onvif.org/ver20/backchannel")
+		}
+
 		if trace {
 			switch msg := msg.(type) {
 			case *tcp.Request:
@@ -169,6 +238,17 @@ func tcpHandler(conn *rtsp.Conn) {
 
 			name = conn.URL.Path[1:]
 
+			query := conn.URL.Query()
+			backchannel := query.Get("backchannel") == "1" || onvifBackchannel
+
+			user := users.Get(conn.Username())
+			if !user.Allow(users.PermView, name) ||
+				backchannel && !user.Allow(users.PermBackchannel, name) {
+				log.Warn().Str("stream", name).Str("user", user.Name()).Msg("[rtsp] forbidden")
+				conn.Forbid()
+				return
+			}
+
 			stream := streams.Get(name)
 			if stream == nil {
 				return
@@ -177,8 +257,6 @@ func tcpHandler(conn *rtsp.Conn) {
 			log.Debug().Str("stream", name).Msg("[rtsp] new consumer")
 
 			conn.SessionName = app.UserAgent
-
-			query := conn.URL.Query()
 			conn.Medias = ParseQuery(query)
 			if conn.Medias == nil {
 				for _, media := range defaultMedias {
@@ -186,7 +264,7 @@ func tcpHandler(conn *rtsp.Conn) {
 				}
 			}
 
-			if query.Get("backchannel") == "1" {
+			if backchannel {
 				conn.Medias = append(conn.Medias, &core.Media{
 					Kind:      core.KindAudio,
 					Direction: core.DirectionRecvonly,
@@ -227,6 +305,27 @@ func tcpHandler(conn *rtsp.Conn) {
 				stream.RemoveConsumer(conn)
 			}
 
+			if multicastAddr != nil {
+				key := name + "?" + conn.URL.RawQuery
+
+				var group *net.UDPAddr
+				conn.OnMulticast = func() (*net.UDPAddr, int, error) {
+					// one join for all tracks of the client
+					if group == nil {
+						addr, err := multicastJoin(key, stream, conn.Medias)
+						if err != nil {
+							return nil, 0, err
+						}
+						group = addr
+						closer = func() {
+							stream.RemoveConsumer(conn)
+							multicastLeave(key)
+						}
+					}
+					return group, multicastTTL, nil
+				}
+			}
+
 		case rtsp.MethodAnnounce:
 			if len(conn.URL.Path) == 0 {
 				log.Warn().Msg("[rtsp] server empty URL on ANNOUNCE")
@@ -235,6 +334,12 @@ func tcpHandler(conn *rtsp.Conn) {
 
 			name = conn.URL.Path[1:]
 
+			if user := users.Get(conn.Username()); !user.Allow(users.PermPublish, name) {
+				log.Warn().Str("stream", name).Str("user", user.Name()).Msg("[rtsp] forbidden")
+				conn.Forbid()
+				return
+			}
+
 			stream := streams.Get(name)
 			if stream == nil {
 				return
@@ -258,7 +363,7 @@ func tcpHandler(conn *rtsp.Conn) {
 	if err := conn.Accept(); err != nil {
 		if errors.Is(err, rtsp.FailedAuth) {
 			log.Warn().Str("remote_addr", conn.Connection.RemoteAddr).Msg("[rtsp] failed authentication")
-		} else if err != io.EOF {
+		} else if err != io.EOF && !errors.Is(err, rtsp.Forbidden) {
 			log.WithLevel(level).Err(err).Caller().Send()
 		}
 		if closer != nil {
@@ -287,6 +392,51 @@ func tcpHandler(conn *rtsp.Conn) {
 	_ = conn.Close()
 }
 
+// streamsConsumerHandle - push stream to the remote RTSP server:
+// rtsp://server/path#transport=udp#video=h264#audio=aac
+func streamsConsumerHandle(rawURL string) (core.Consumer, func(), error) {
+	rawURL, rawQuery, _ := strings.Cut(rawURL, "#")
+
+	query := streams.ParseQuery(rawQuery)
+
+	medias := ParseQuery(query)
+	if medias == nil {
+		for _, media := range defaultMedias {
+			medias = append(medias, media.Clone())
+		}
+	}
+
+	conn := rtsp.NewPublishClient(rawURL, medias)
+	conn.SessionName = app.UserAgent
+	conn.UserAgent = app.UserAgent
+	conn.Timeout = core.Atoi(query.Get("timeout"))
+	conn.Transport = query.Get("transport")
+
+	switch conn.Transport {
+	case "", "tcp", "udp":
+	default:
+		return nil, nil, errors.New("rtsp: unsupported publish transport: " + conn.Transport)
+	}
+
+	if s := query.Get("pkt_size"); s != "" {
+		conn.PacketSize = uint16(core.Atoi(s))
+	}
+
+	run := func() {
+		if err := conn.Publish(); err != nil {
+			log.Debug().Err(err).Str("url", rawURL).Msg("[rtsp] publish")
+			_ = conn.Stop()
+			return
+		}
+		if err := conn.Handle(); err != nil {
+			log.Debug().Err(err).Str("url", rawURL).Msg("[rtsp] publish")
+		}
+		_ = conn.Stop()
+	}
+
+	return conn, run, nil
+}
+
 func ParseQuery(query map[string][]string) []*core.Media {
 	if v := query["mp4"]; v != nil {
 		return []*core.Media{
@@ -0,0 +1,196 @@
+package srt
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/internal/users"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/mpegts"
+	"github.com/AlexxIT/go2rtc/pkg/srt"
+	"github.com/rs/zerolog"
+)
+
+func Init() {
+	var conf struct {
+		Mod struct {
+			Listen     string `yaml:"listen" json:"listen"`
+			Latency    int    `yaml:"latency" json:"latency,omitempty"`
+			Passphrase string `yaml:"passphrase" json:"-"`
+		} `yaml:"srt"`
+	}
+
+	app.LoadConfig(&conf)
+
+	log = app.GetLogger("srt")
+
+	streams.HandleFunc("srt", streamsHandle)
+
+	streams.HandleConsumerFunc("srt", streamsConsumerHandle)
+
+	address := conf.Mod.Listen
+	if address == "" {
+		return
+	}
+
+	ln, err := srt.Listen(address)
+	if err != nil {
+		log.Error().Err(err).Msg("[srt] listen")
+		return
+	}
+
+	if conf.Mod.Latency > 0 {
+		ln.Latency = time.Duration(conf.Mod.Latency) * time.Millisecond
+	}
+	ln.Passphrase = conf.Mod.Passphrase
+	ln.Authorize = authorize
+
+	log.Info().Str("addr", address).Msg("[srt] listen")
+
+	go serve(ln)
+}
+
+var log zerolog.Logger
+
+func serve(ln *srt.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			if err = handle(conn); err != nil {
+				log.Warn().Err(err).Str("remote_addr", conn.RemoteAddr().String()).Caller().Send()
+			}
+			_ = conn.Close()
+		}()
+	}
+}
+
+// authorize - check stream ID before accepting connection
+func authorize(streamID string, addr net.Addr) uint32 {
+	id := parseStreamID(streamID)
+	if id.name == "" {
+		return srt.RejectBadRequest
+	}
+
+	if streams.Get(id.name) == nil {
+		return srt.RejectNotFound
+	}
+
+	if !users.Enabled() {
+		return 0
+	}
+
+	// skip check auth for localhost
+	if addr, ok := addr.(*net.UDPAddr); ok && addr.IP.IsLoopback() {
+		return 0
+	}
+
+	user := users.Auth(id.user, id.pass)
+	if user == nil {
+		log.Debug().Str("remote_addr", addr.String()).Str("stream", id.name).Msg("[srt] failed authentication")
+		return srt.RejectUnauthorized
+	}
+
+	perm := users.PermView
+	if id.mode == modePublish {
+		perm = users.PermPublish
+	}
+
+	if !user.Allow(perm, id.name) {
+		log.Debug().Msgf("[srt] forbidden user=%s stream=%s", id.user, id.name)
+		return srt.RejectForbidden
+	}
+
+	return 0
+}
+
+func handle(conn *srt.Conn) error {
+	id := parseStreamID(conn.StreamID)
+
+	stream := streams.Get(id.name)
+	if stream == nil {
+		return errors.New("stream not found: " + id.name)
+	}
+
+	if id.mode == modePublish {
+		prod, err := mpegts.Open(conn)
+		if err != nil {
+			return err
+		}
+
+		prod.Protocol = "srt"
+		prod.RemoteAddr = conn.RemoteAddr().String()
+
+		stream.AddProducer(prod)
+		defer stream.RemoveProducer(prod)
+
+		_ = prod.Start()
+
+		return nil
+	}
+
+	cons := mpegts.NewConsumer()
+	cons.Protocol = "srt"
+	cons.RemoteAddr = conn.RemoteAddr().String()
+
+	if err := stream.AddConsumer(cons); err != nil {
+		return err
+	}
+
+	defer stream.RemoveConsumer(cons)
+
+	_, _ = cons.WriteTo(conn)
+
+	return nil
+}
+
+// streamsHandle - SRT caller: srt://host:port?streamid=camera1&passphrase=secret123456
+func streamsHandle(rawURL string) (core.Producer, error) {
+	conn, err := srt.Dial(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	prod, err := mpegts.Open(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	prod.Protocol = "srt"
+	prod.RemoteAddr = conn.RemoteAddr().String()
+	prod.URL = rawURL
+
+	return prod, nil
+}
+
+// streamsConsumerHandle - publish stream to the SRT listener:
+// srt://host:port?streamid=publish:camera1
+func streamsConsumerHandle(rawURL string) (core.Consumer, func(), error) {
+	cons := mpegts.NewConsumer()
+	cons.Protocol = "srt"
+	cons.URL = rawURL
+
+	run := func() {
+		conn, err := srt.Dial(rawURL)
+		if err != nil {
+			log.Debug().Err(err).Str("url", rawURL).Msg("[srt] publish")
+			return
+		}
+
+		cons.RemoteAddr = conn.RemoteAddr().String()
+
+		_, err = cons.WriteTo(conn)
+		log.Debug().Err(err).Str("url", rawURL).Msg("[srt] publish")
+
+		_ = conn.Close()
+	}
+
+	return cons, run, nil
+}
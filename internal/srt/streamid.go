@@ -0,0 +1,62 @@
+package srt
+
+import (
+	"strings"
+)
+
+const (
+	modeRead    = "read"
+	modePublish = "publish"
+)
+
+type streamID struct {
+	name string
+	mode string
+	user string
+	pass string
+}
+
+// parseStreamID support formats:
+//   - camera1
+//   - read:camera1, publish:camera1:user:pass
+//   - #!::r=camera1,m=publish,u=user,p=pass (SRT access control)
+func parseStreamID(s string) (id streamID) {
+	id.mode = modeRead
+
+	if strings.HasPrefix(s, "#!::") {
+		for _, kv := range strings.Split(s[4:], ",") {
+			k, v, _ := strings.Cut(kv, "=")
+			switch k {
+			case "r":
+				id.name = v
+			case "m":
+				if v == modePublish {
+					id.mode = modePublish
+				}
+			case "u":
+				id.user = v
+			case "p":
+				id.pass = v
+			}
+		}
+		return
+	}
+
+	fields := strings.Split(s, ":")
+
+	switch fields[0] {
+	case modeRead, modePublish:
+		id.mode = fields[0]
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 {
+		id.name = fields[0]
+	}
+	if len(fields) > 2 {
+		id.user = fields[1]
+		id.pass = fields[2]
+	}
+
+	return
+}
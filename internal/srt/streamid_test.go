@@ -0,0 +1,17 @@
+package srt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStreamID(t *testing.T) {
+	require.Equal(t, streamID{name: "camera1", mode: modeRead}, parseStreamID("camera1"))
+	require.Equal(t, streamID{name: "camera1", mode: modeRead}, parseStreamID("read:camera1"))
+	require.Equal(t, streamID{name: "camera1", mode: modePublish, user: "admin", pass: "secret"},
+		parseStreamID("publish:camera1:admin:secret"))
+	require.Equal(t, streamID{name: "camera1", mode: modePublish, user: "admin", pass: "secret"},
+		parseStreamID("#!::r=camera1,m=publish,u=admin,p=secret"))
+	require.Equal(t, streamID{name: "camera1", mode: modeRead}, parseStreamID("#!::m=request,r=camera1"))
+}
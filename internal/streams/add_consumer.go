@@ -99,13 +99,17 @@ func (s *Stream) AddConsumer(cons core.Consumer) (err error) {
 	}
 
 	if len(prodStarts) == 0 {
-		return formatError(consMedias, prodMedias, prodErrors)
+		err = formatError(consMedias, prodMedias, prodErrors)
+		s.fireEvent(&Event{Type: EventConsumerError, Format: consumerFormat(cons), Error: err.Error()})
+		return err
 	}
 
 	s.mu.Lock()
 	s.consumers = append(s.consumers, cons)
 	s.mu.Unlock()
 
+	s.fireEvent(&Event{Type: EventConsumerAdd, Format: consumerFormat(cons)})
+
 	// there may be duplicates, but that's not a problem
 	for _, prod := range prodStarts {
 		prod.start()
@@ -5,6 +5,7 @@ import (
 
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/internal/users"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/creds"
 	"github.com/AlexxIT/go2rtc/pkg/probe"
@@ -25,7 +26,11 @@ func apiStreams(w http.ResponseWriter, r *http.Request) {
 
 	// without source - return all streams list
 	if src == "" && r.Method != "POST" {
-		api.ResponseJSON(w, streams)
+		if user := users.FromRequest(r); user != nil {
+			api.ResponseJSON(w, allowedStreams(user))
+		} else {
+			api.ResponseJSON(w, streams)
+		}
 		return
 	}
 
@@ -113,6 +118,20 @@ func apiStreams(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// allowedStreams return streams that the user can view
+func allowedStreams(user *users.User) map[string]*Stream {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+
+	items := map[string]*Stream{}
+	for name, stream := range streams {
+		if user.Allow(users.PermView, name) {
+			items[name] = stream
+		}
+	}
+	return items
+}
+
 func apiStreamsDOT(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
@@ -0,0 +1,77 @@
+package streams
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+// Backoff - retry policy for sources reconnect and publish
+type Backoff struct {
+	Initial time.Duration // first delay, zero for default schedule
+	Max     time.Duration // max delay, zero for unlimited
+	Jitter  float64       // random part of the delay from 0 to 1
+	GiveUp  time.Duration // stop retries after this time without success, zero for never
+}
+
+// defaultBackoff - global policy from config
+var defaultBackoff Backoff
+
+// Delay return timeout before retry number (starting from zero)
+func (b *Backoff) Delay(retry int) time.Duration {
+	if b.Initial <= 0 {
+		switch {
+		case retry < 5:
+			return time.Second
+		case retry < 10:
+			return time.Second * 5
+		case retry < 20:
+			return time.Second * 10
+		}
+		return time.Minute
+	}
+
+	// exponential delay with overflow protection
+	d := b.Initial
+	for i := 0; i < retry && (b.Max == 0 || d < b.Max); i++ {
+		if d > time.Hour*24 {
+			break
+		}
+		d *= 2
+	}
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+
+	if b.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * b.Jitter * float64(d))
+	}
+
+	return d
+}
+
+// parseBackoff updates policy from config map
+func parseBackoff(v any, b Backoff) Backoff {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return b
+	}
+
+	for k, v := range m {
+		s := fmt.Sprint(v)
+		switch k {
+		case "initial":
+			b.Initial = ParseDuration(s)
+		case "max":
+			b.Max = ParseDuration(s)
+		case "jitter":
+			b.Jitter, _ = strconv.ParseFloat(s, 64)
+			b.Jitter = min(max(b.Jitter, 0), 1) // bigger jitter can make negative delay
+		case "give_up":
+			b.GiveUp = ParseDuration(s)
+		}
+	}
+
+	return b
+}
@@ -0,0 +1,40 @@
+package streams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	// default schedule
+	b := &Backoff{}
+	require.Equal(t, time.Second, b.Delay(0))
+	require.Equal(t, 5*time.Second, b.Delay(5))
+	require.Equal(t, time.Minute, b.Delay(100))
+
+	b = &Backoff{Initial: time.Second, Max: 30 * time.Second}
+	require.Equal(t, time.Second, b.Delay(0))
+	require.Equal(t, 8*time.Second, b.Delay(3))
+	require.Equal(t, 30*time.Second, b.Delay(10))
+	require.Equal(t, 30*time.Second, b.Delay(1000))
+
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := b.Delay(10)
+		require.GreaterOrEqual(t, d, 15*time.Second)
+		require.LessOrEqual(t, d, 45*time.Second)
+	}
+
+	parsed := parseBackoff(map[string]any{"initial": "2s", "max": 60, "jitter": 0.1, "give_up": "1h"}, Backoff{})
+	require.Equal(t, Backoff{Initial: 2 * time.Second, Max: time.Minute, Jitter: 0.1, GiveUp: time.Hour}, parsed)
+
+	require.Equal(t, 1.0, parseBackoff(map[string]any{"jitter": 5}, Backoff{}).Jitter)
+	require.Equal(t, 0.0, parseBackoff(map[string]any{"jitter": -1}, Backoff{}).Jitter)
+}
+
+func TestAppendJSON(t *testing.T) {
+	require.Equal(t, `{"a":1,"b":2}`, string(appendJSON([]byte(`{"a":1}`), "b", []byte("2"))))
+	require.Equal(t, `{"b":2}`, string(appendJSON([]byte(`{}`), "b", []byte("2"))))
+}
@@ -0,0 +1,133 @@
+package streams
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+// Event types
+const (
+	EventProducerOnline    = "producer_online"
+	EventProducerOffline   = "producer_offline"
+	EventProducerReconnect = "producer_reconnect"
+	EventProducerStall     = "producer_stall"
+	EventProducerGiveUp    = "producer_give_up"
+	EventProducerStop      = "producer_stop"
+	EventFailover          = "failover"
+	EventConsumerAdd       = "consumer_add"
+	EventConsumerRemove    = "consumer_remove"
+	EventConsumerError     = "consumer_error"
+	EventPublishError      = "publish_error"
+)
+
+// Event - stream lifecycle event
+type Event struct {
+	Type   string    `json:"type"`
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream,omitempty"`
+	URL    string    `json:"url,omitempty"`    // producer or publish URL without credentials
+	Format string    `json:"format,omitempty"` // consumer format
+	Retry  int       `json:"retry,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Subscribe adds listener for all streams events and return function for
+// unsubscribe. Events are delivered in order from a single goroutine, so the
+// listener should be fast.
+func Subscribe(f func(event *Event)) func() {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+
+	if events == nil {
+		events = make(chan *Event, 100)
+		go dispatch()
+	}
+
+	listenersID++
+	id := listenersID
+	listeners[id] = f
+
+	return func() {
+		listenersMu.Lock()
+		delete(listeners, id)
+		listenersMu.Unlock()
+	}
+}
+
+var (
+	events      chan *Event
+	listeners   = map[int]func(event *Event){}
+	listenersID int
+	listenersMu sync.Mutex
+)
+
+func dispatch() {
+	for event := range events {
+		listenersMu.Lock()
+		items := make([]func(event *Event), 0, len(listeners))
+		for _, f := range listeners {
+			items = append(items, f)
+		}
+		listenersMu.Unlock()
+
+		for _, f := range items {
+			f(event)
+		}
+	}
+}
+
+func fireEvent(event *Event) {
+	listenersMu.Lock()
+	ch := events
+	listenersMu.Unlock()
+
+	if ch == nil {
+		return // no listeners
+	}
+
+	event.Time = time.Now()
+	event.URL = hideCredentials(event.URL)
+
+	select {
+	case ch <- event:
+	default:
+		log.Warn().Str("type", event.Type).Msg("[streams] events queue is full")
+	}
+}
+
+func (s *Stream) fireEvent(event *Event) {
+	event.Stream = s.name
+	fireEvent(event)
+}
+
+// listenProducer converts producer messages to stream events
+func (s *Stream) listenProducer(prod *Producer) {
+	prod.Listen(func(msg any) {
+		if event, ok := msg.(*Event); ok {
+			s.fireEvent(event)
+
+			if event.Type == EventProducerGiveUp {
+				// event is fired under the producer lock
+				go s.removeOrphanConsumers()
+			}
+		}
+	})
+}
+
+func consumerFormat(cons core.Consumer) string {
+	if c, ok := cons.(connection); ok {
+		return c.GetConnection().FormatName
+	}
+	return ""
+}
+
+func hideCredentials(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.User != nil {
+		u.User = nil
+		return u.String()
+	}
+	return rawURL
+}
@@ -0,0 +1,265 @@
+package streams
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+// Failover moves stream tracks from the primary (first) source to the next
+// working source when the primary fails and back when it recovers.
+type Failover struct {
+	Retries int           // failed reconnects before switch
+	Timeout time.Duration // max silence before switch and min activity before switch back
+
+	primary *Producer
+	backup  *Producer        // active backup source, nil if primary is active
+	tracks  []*core.Receiver // backup track for each primary receiver
+	since   time.Time
+
+	packets int       // primary packets on last check
+	changed time.Time // last time when primary packets changed
+	stalled time.Time // last time when primary packets not changed
+
+	mu sync.Mutex
+}
+
+func newFailover(v any) *Failover {
+	f := &Failover{Retries: 3, Timeout: 10 * time.Second}
+
+	switch v := v.(type) {
+	case bool:
+		if !v {
+			return nil
+		}
+	case map[string]any:
+		if retries, ok := v["retries"].(int); ok {
+			f.Retries = retries
+		}
+		if timeout := v["timeout"]; timeout != nil {
+			f.Timeout = ParseDuration(fmt.Sprint(timeout))
+		}
+	default:
+		return nil
+	}
+
+	failoverOnce.Do(func() {
+		go failoverWatcher()
+	})
+
+	return f
+}
+
+var failoverOnce sync.Once
+
+func failoverWatcher() {
+	for now := range time.Tick(time.Second) {
+		var items []*Stream
+
+		streamsMu.Lock()
+		for _, stream := range streams {
+			if stream.failover != nil && !containsStream(items, stream) {
+				items = append(items, stream) // skip aliases
+			}
+		}
+		streamsMu.Unlock()
+
+		for _, stream := range items {
+			if stream.checkFailover(now) {
+				stream.stopProducers()
+			}
+		}
+	}
+}
+
+func containsStream(items []*Stream, stream *Stream) bool {
+	for _, item := range items {
+		if item == stream {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFailover return true if backup source was released
+func (s *Stream) checkFailover(now time.Time) bool {
+	s.mu.Lock()
+	producers := s.producers
+	s.mu.Unlock()
+
+	if len(producers) < 2 {
+		return false
+	}
+
+	primary := producers[0]
+
+	retry := primary.retries()
+
+	primary.mu.Lock()
+	state := primary.state
+	receivers := primary.receivers
+	var packets int
+	for _, receiver := range receivers {
+		_, n := receiver.Stats()
+		packets += n
+	}
+	primary.mu.Unlock()
+
+	f := s.failover
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.primary = primary
+
+	if packets != f.packets {
+		f.packets = packets
+		f.changed = now
+	} else {
+		f.stalled = now
+	}
+
+	if f.backup == nil {
+		if state != stateStart {
+			f.changed = now
+			return false
+		}
+
+		if retry < f.Retries && now.Sub(f.changed) < f.Timeout {
+			return false
+		}
+
+		for _, backup := range producers[1:] {
+			if backup.getState() == stateExternal {
+				continue
+			}
+			if err := f.switchTo(backup, receivers); err != nil {
+				log.Debug().Err(err).Str("url", backup.url).Msg("[streams] failover")
+				continue
+			}
+			log.Info().Str("from", primary.url).Str("to", backup.url).Msg("[streams] failover")
+			f.since = now
+			s.fireEvent(&Event{Type: EventFailover, URL: backup.url})
+			return false
+		}
+
+		return false
+	}
+
+	// backup was stopped because there are no consumers
+	if f.backup.getState() == stateNone {
+		f.reset()
+		return false
+	}
+
+	if state != stateStart || retry > 0 || now.Sub(f.stalled) < f.Timeout {
+		// move new consumers of the primary source to the backup
+		for i, receiver := range receivers {
+			if i < len(f.tracks) {
+				receiver.Replace(f.tracks[i])
+			}
+		}
+		return false
+	}
+
+	log.Info().Str("from", f.backup.url).Str("to", primary.url).Msg("[streams] failover recovered")
+
+	for i, track := range f.tracks {
+		if i < len(receivers) {
+			track.Replace(receivers[i])
+		}
+	}
+
+	f.reset()
+	f.since = now
+	s.fireEvent(&Event{Type: EventFailover, URL: primary.url})
+	return true
+}
+
+func (f *Failover) switchTo(backup *Producer, receivers []*core.Receiver) error {
+	if err := backup.Dial(); err != nil {
+		return err
+	}
+
+	medias := backup.GetMedias()
+
+	var tracks []*core.Receiver
+
+	for _, receiver := range receivers {
+		var track *core.Receiver
+
+		for _, media := range medias {
+			if media.Direction != core.DirectionRecvonly {
+				continue
+			}
+			if codec := media.MatchCodec(receiver.Codec); codec != nil {
+				if track, _ = backup.GetTrack(media, codec); track != nil {
+					break
+				}
+			}
+		}
+
+		if track == nil {
+			if !backup.hasReaders() {
+				backup.stop()
+			}
+			return errors.New("streams: codecs not matched: " + receiver.Codec.String())
+		}
+
+		tracks = append(tracks, track)
+	}
+
+	for i, receiver := range receivers {
+		receiver.Replace(tracks[i])
+	}
+
+	backup.start()
+
+	f.backup = backup
+	f.tracks = tracks
+	return nil
+}
+
+func (f *Failover) reset() {
+	f.backup = nil
+	f.tracks = nil
+}
+
+// keep return true if producer is failed primary source and it should wait
+// for recovery while the backup source has readers
+func (f *Failover) keep(producer *Producer) bool {
+	if f == nil {
+		return false
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.backup != nil && f.primary == producer && f.backup.hasReaders()
+}
+
+func (f *Failover) MarshalJSON() ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info := struct {
+		Active string     `json:"active,omitempty"`
+		Since  *time.Time `json:"since,omitempty"`
+	}{}
+
+	if f.backup != nil {
+		info.Active = f.backup.url
+	} else if f.primary != nil {
+		info.Active = f.primary.url
+	}
+
+	if !f.since.IsZero() {
+		info.Since = &f.since
+	}
+
+	return json.Marshal(info)
+}
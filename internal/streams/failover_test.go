@@ -0,0 +1,123 @@
+package streams
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/stretchr/testify/require"
+)
+
+type testProducer struct {
+	medias    []*core.Media
+	receivers []*core.Receiver
+	done      chan struct{}
+	once      sync.Once
+}
+
+func newTestProducer() *testProducer {
+	return &testProducer{
+		medias: []*core.Media{{
+			Kind:      core.KindVideo,
+			Direction: core.DirectionRecvonly,
+			Codecs:    []*core.Codec{{Name: core.CodecH264, ClockRate: 90000}},
+		}},
+		done: make(chan struct{}),
+	}
+}
+
+func (p *testProducer) GetMedias() []*core.Media {
+	return p.medias
+}
+
+func (p *testProducer) GetTrack(media *core.Media, codec *core.Codec) (*core.Receiver, error) {
+	receiver := core.NewReceiver(media, codec)
+	p.receivers = append(p.receivers, receiver)
+	return receiver, nil
+}
+
+func (p *testProducer) Start() error {
+	<-p.done
+	return nil
+}
+
+func (p *testProducer) Stop() error {
+	p.once.Do(func() { close(p.done) })
+	return nil
+}
+
+type testConsumer struct {
+	medias  []*core.Media
+	senders []*core.Sender
+}
+
+func (c *testConsumer) GetMedias() []*core.Media {
+	return c.medias
+}
+
+func (c *testConsumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiver) error {
+	sender := core.NewSender(media, track.Codec)
+	sender.Handler = func(*core.Packet) {}
+	sender.HandleRTP(track)
+	c.senders = append(c.senders, sender)
+	return nil
+}
+
+func (c *testConsumer) Stop() error {
+	for _, sender := range c.senders {
+		sender.Close()
+	}
+	return nil
+}
+
+func TestFailover(t *testing.T) {
+	prods := map[string]*testProducer{"test:primary": newTestProducer(), "test:backup": newTestProducer()}
+	HandleFunc("test", func(url string) (core.Producer, error) { return prods[url], nil })
+
+	stream := NewStream(map[string]any{
+		"url":      []any{"test:primary", "test:backup"},
+		"failover": map[string]any{"retries": 1, "timeout": "10s"},
+	})
+	require.NotNil(t, stream.failover)
+
+	cons := &testConsumer{medias: []*core.Media{{
+		Kind:      core.KindVideo,
+		Direction: core.DirectionSendonly,
+		Codecs:    []*core.Codec{{Name: core.CodecH264}},
+	}}}
+	require.NoError(t, stream.AddConsumer(cons))
+
+	primary := prods["test:primary"].receivers[0]
+	require.Len(t, primary.Senders(), 1)
+
+	now := time.Now()
+	primary.Input(&core.Packet{})
+	require.False(t, stream.checkFailover(now))
+
+	// primary stops sending packets
+	require.False(t, stream.checkFailover(now.Add(11*time.Second)))
+	require.Len(t, primary.Senders(), 0)
+
+	backup := prods["test:backup"].receivers[0]
+	require.Len(t, backup.Senders(), 1)
+	require.Equal(t, "test:backup", stream.failover.backup.url)
+
+	// primary sends packets again, but not long enough
+	now = now.Add(12 * time.Second)
+	primary.Input(&core.Packet{})
+	require.False(t, stream.checkFailover(now))
+
+	// switch back after 10 seconds of activity since the last silence
+	for i := 1; i <= 9; i++ {
+		primary.Input(&core.Packet{})
+		recovered := stream.checkFailover(now.Add(time.Duration(i) * time.Second))
+		require.Equal(t, i == 9, recovered)
+	}
+
+	require.Len(t, primary.Senders(), 1)
+	require.Len(t, backup.Senders(), 0)
+	require.Nil(t, stream.failover.backup)
+
+	stream.RemoveConsumer(cons)
+}
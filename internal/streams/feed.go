@@ -0,0 +1,247 @@
+package streams
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/api/ws"
+	"github.com/AlexxIT/go2rtc/internal/users"
+)
+
+// Stats - stream state sample for the feed
+type Stats struct {
+	Producers   int `json:"producers"`    // online producers
+	Consumers   int `json:"consumers"`    // all consumers
+	RecvBytes   int `json:"recv_bytes"`   // total bytes received from online producers
+	SendBytes   int `json:"send_bytes"`   // total bytes sent to consumers
+	RecvBitrate int `json:"recv_bitrate"` // bits per second since the previous sample
+	SendBitrate int `json:"send_bitrate"` // bits per second since the previous sample
+}
+
+const feedInterval = 5 * time.Second
+
+// feedMinInterval - protection from the stats flood by the client
+const feedMinInterval = time.Second
+
+// subscribeFeed sends events and stats of streams matched by the match function
+// to the write function until stop function is called.
+// Write is called from a single goroutine, events are dropped for slow clients.
+func subscribeFeed(match func(name string) bool, interval time.Duration, write func(msgType string, value any)) (stop func()) {
+	if interval <= 0 {
+		interval = feedInterval
+	} else if interval < feedMinInterval {
+		interval = feedMinInterval
+	}
+
+	events := make(chan *Event, 100)
+
+	unsubscribe := Subscribe(func(event *Event) {
+		if !match(event.Stream) {
+			return
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	})
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		prev := collectStats(match, nil, 0)
+
+		for {
+			select {
+			case event := <-events:
+				write("streams/event", event)
+			case <-ticker.C:
+				stats := collectStats(match, prev, interval)
+				write("streams/stats", stats)
+				prev = stats
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		unsubscribe()
+		close(done)
+		<-exited
+	}
+}
+
+// feedMatch return filter for the stream names (all if names is empty)
+// and streams allowed to view for the user
+func feedMatch(names []string, user *users.User) func(name string) bool {
+	return func(name string) bool {
+		if len(names) > 0 && !slices.Contains(names, name) {
+			return false
+		}
+		return user.Allow(users.PermView, name)
+	}
+}
+
+// collectStats return stats for streams and calculate bitrate from the previous sample
+func collectStats(match func(name string) bool, prev map[string]*Stats, elapsed time.Duration) map[string]*Stats {
+	items, streamNames := namedStreams()
+
+	stats := map[string]*Stats{}
+
+	for _, stream := range items {
+		name := streamNames[stream]
+		if !match(name) {
+			continue
+		}
+
+		stream.mu.Lock()
+		producers := stream.producers
+		consumers := stream.consumers
+		stream.mu.Unlock()
+
+		item := &Stats{Consumers: len(consumers)}
+
+		for _, prod := range producers {
+			prod.mu.Lock()
+			if prod.conn != nil {
+				item.Producers++
+				for _, receiver := range prod.receivers {
+					bytes, _ := receiver.Stats()
+					item.RecvBytes += bytes
+				}
+			}
+			prod.mu.Unlock()
+		}
+
+		for _, cons := range consumers {
+			if c, ok := cons.(connection); ok {
+				for _, sender := range c.GetConnection().Senders {
+					bytes, _, _ := sender.Stats()
+					item.SendBytes += bytes
+				}
+			}
+		}
+
+		if p := prev[name]; p != nil && elapsed > 0 {
+			item.RecvBitrate = bitrate(item.RecvBytes, p.RecvBytes, elapsed)
+			item.SendBitrate = bitrate(item.SendBytes, p.SendBytes, elapsed)
+		}
+
+		stats[name] = item
+	}
+
+	return stats
+}
+
+func bitrate(bytes, prev int, elapsed time.Duration) int {
+	// counters reset when connections change
+	if bytes < prev {
+		prev = 0
+	}
+	return int(float64(bytes-prev) * 8 / elapsed.Seconds())
+}
+
+// feedSubscription - WebSocket transport can have only one feed subscription
+type feedSubscription struct {
+	stop func()
+	mu   sync.Mutex
+}
+
+func (f *feedSubscription) replace(stop func()) {
+	f.mu.Lock()
+	if f.stop != nil {
+		f.stop()
+	}
+	f.stop = stop
+	f.mu.Unlock()
+}
+
+type feedKey struct{}
+
+func getFeedSubscription(tr *ws.Transport) (sub *feedSubscription) {
+	var created bool
+	tr.WithContext(func(ctx map[any]any) {
+		if sub, _ = ctx[feedKey{}].(*feedSubscription); sub == nil {
+			sub = &feedSubscription{}
+			ctx[feedKey{}] = sub
+			created = true
+		}
+	})
+	if created {
+		tr.OnClose(func() {
+			sub.replace(nil)
+		})
+	}
+	return
+}
+
+// wsFeed - subscribe to the feed via WebSocket API
+func wsFeed(tr *ws.Transport, msg *ws.Message) error {
+	var req struct {
+		Src      []string `json:"src"`
+		Interval float64  `json:"interval"` // seconds
+	}
+	if msg.Value != nil {
+		if err := msg.Unmarshal(&req); err != nil {
+			return err
+		}
+	}
+
+	stop := subscribeFeed(feedMatch(req.Src, users.FromRequest(tr.Request)), time.Duration(req.Interval*float64(time.Second)), func(msgType string, value any) {
+		tr.Write(&ws.Message{Type: msgType, Value: value})
+	})
+
+	getFeedSubscription(tr).replace(stop)
+
+	return nil
+}
+
+// wsFeedStop - unsubscribe from the feed via WebSocket API
+func wsFeedStop(tr *ws.Transport, _ *ws.Message) error {
+	getFeedSubscription(tr).replace(nil)
+	return nil
+}
+
+// apiFeed - subscribe to the feed via Server-Sent Events
+func apiFeed(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var interval time.Duration
+	if s := query.Get("interval"); s != "" {
+		interval = ParseDuration(s)
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	stop := subscribeFeed(feedMatch(query["src"], users.FromRequest(r)), interval, func(msgType string, value any) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return
+		}
+		_, _ = w.Write([]byte("event: " + msgType + "\ndata: " + string(data) + "\n\n"))
+		flusher.Flush()
+	})
+
+	<-r.Context().Done()
+
+	stop()
+}
@@ -0,0 +1,55 @@
+package streams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeed(t *testing.T) {
+	stream := NewStream("test:feed")
+	stream.name = "feed"
+
+	streamsMu.Lock()
+	streams["feed"] = stream
+	streamsMu.Unlock()
+
+	defer Delete("feed")
+
+	messages := make(chan any, 10)
+	// interval will be limited to feedMinInterval
+	stop := subscribeFeed(feedMatch([]string{"feed"}, nil), 10*time.Millisecond, func(msgType string, value any) {
+		messages <- value
+	})
+	defer stop()
+
+	// event from other stream should be skipped
+	fireEvent(&Event{Type: EventConsumerAdd, Stream: "other"})
+	stream.fireEvent(&Event{Type: EventConsumerAdd, Format: "rtsp"})
+
+	var event *Event
+	var stats map[string]*Stats
+
+	for event == nil || stats == nil {
+		select {
+		case msg := <-messages:
+			switch msg := msg.(type) {
+			case *Event:
+				event = msg
+			case map[string]*Stats:
+				stats = msg
+			}
+		case <-time.After(feedMinInterval + time.Second):
+			require.FailNow(t, "no feed messages")
+		}
+	}
+
+	require.Equal(t, "feed", event.Stream)
+	require.Equal(t, "rtsp", event.Format)
+	require.Len(t, stats, 1)
+	require.NotNil(t, stats["feed"])
+
+	require.Equal(t, 8000, bitrate(2000, 1000, time.Second))
+	require.Equal(t, 8000, bitrate(1000, 5000, time.Second))
+}
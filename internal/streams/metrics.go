@@ -0,0 +1,123 @@
+package streams
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/AlexxIT/go2rtc/internal/metrics"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+type connection interface {
+	GetConnection() *core.Connection
+}
+
+type sentKey struct {
+	format, codec string
+}
+
+type sentValue struct {
+	bytes, packets, drops int
+}
+
+// sentStats - consumers counters by format and codec, without connection ID
+// because it makes unlimited number of metrics series
+type sentStats map[sentKey]sentValue
+
+func (s sentStats) add(cons core.Consumer) sentStats {
+	c, ok := cons.(connection)
+	if !ok {
+		return s
+	}
+
+	info := c.GetConnection()
+
+	for _, sender := range info.Senders {
+		if s == nil {
+			s = sentStats{}
+		}
+		key := sentKey{format: info.FormatName, codec: sender.Codec.Name}
+		bytes, packets, drops := sender.Stats()
+		v := s[key]
+		v.bytes += bytes
+		v.packets += packets
+		v.drops += drops
+		s[key] = v
+	}
+
+	return s
+}
+
+func collectMetrics(m *metrics.Metrics) {
+	items, names := namedStreams()
+
+	m.Gauge("go2rtc_streams", "Number of streams", float64(len(items)))
+
+	sessions := map[string]int{}
+
+	for _, stream := range items {
+		name := names[stream]
+
+		stream.mu.Lock()
+		producers := stream.producers
+		consumers := stream.consumers
+		sent := maps.Clone(stream.sent) // removed consumers
+		stream.mu.Unlock()
+
+		var online int
+
+		for i, prod := range producers {
+			id := strconv.Itoa(i)
+
+			prod.retryMu.Lock()
+			reconnects := prod.reconnects
+			prod.retryMu.Unlock()
+
+			m.Counter("go2rtc_producer_reconnects_total", "Number of producer reconnects", float64(reconnects), "stream", name, "producer", id)
+
+			prod.mu.Lock()
+			conn := prod.conn
+			receivers := prod.receivers
+			prod.mu.Unlock()
+
+			if conn == nil {
+				continue
+			}
+
+			online++
+
+			if c, ok := conn.(connection); ok {
+				sessions[c.GetConnection().FormatName]++
+			}
+
+			for _, receiver := range receivers {
+				codec := receiver.Codec.Name
+				bytes, packets := receiver.Stats()
+				m.Counter("go2rtc_receiver_bytes_total", "Bytes received from producer track", float64(bytes), "stream", name, "producer", id, "codec", codec)
+				m.Counter("go2rtc_receiver_packets_total", "Packets received from producer track", float64(packets), "stream", name, "producer", id, "codec", codec)
+			}
+		}
+
+		m.Gauge("go2rtc_stream_producers", "Number of online producers", float64(online), "stream", name)
+		m.Gauge("go2rtc_stream_consumers", "Number of consumers", float64(len(consumers)), "stream", name)
+
+		// sum of removed and active consumers, so counters don't go down
+		for _, cons := range consumers {
+			if c, ok := cons.(connection); ok {
+				sessions[c.GetConnection().FormatName]++
+			}
+			sent = sent.add(cons)
+		}
+
+		for key, v := range sent {
+			labels := []string{"stream", name, "format", key.format, "codec", key.codec}
+			m.Counter("go2rtc_sender_bytes_total", "Bytes sent to consumers", float64(v.bytes), labels...)
+			m.Counter("go2rtc_sender_packets_total", "Packets sent to consumers", float64(v.packets), labels...)
+			m.Counter("go2rtc_sender_drops_total", "Packets dropped because of slow consumers", float64(v.drops), labels...)
+		}
+	}
+
+	for format, n := range sessions {
+		m.Gauge("go2rtc_sessions", "Number of producer and consumer connections by format", float64(n), "format", format)
+	}
+}
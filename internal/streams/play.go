@@ -119,6 +119,8 @@ func (s *Stream) AddInternalConsumer(conn core.Consumer) {
 	s.mu.Lock()
 	s.consumers = append(s.consumers, conn)
 	s.mu.Unlock()
+
+	s.fireEvent(&Event{Type: EventConsumerAdd, Format: consumerFormat(conn)})
 }
 
 func (s *Stream) RemoveInternalConsumer(conn core.Consumer) {
@@ -130,6 +132,8 @@ func (s *Stream) RemoveInternalConsumer(conn core.Consumer) {
 		}
 	}
 	s.mu.Unlock()
+
+	s.fireEvent(&Event{Type: EventConsumerRemove, Format: consumerFormat(conn)})
 }
 
 func matchMedia(prod core.Producer, cons core.Consumer) bool {
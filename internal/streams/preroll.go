@@ -0,0 +1,65 @@
+package streams
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/preroll"
+)
+
+func AddPreroll(name string, duration time.Duration) error {
+	stream := Get(name)
+	if stream == nil {
+		return fmt.Errorf("streams: stream not found: %s", name)
+	}
+
+	buf := preroll.NewBuffer(duration)
+	if err := stream.AddConsumer(buf); err != nil {
+		return err
+	}
+
+	stream.mu.Lock()
+	prev := stream.preroll
+	stream.preroll = buf
+	stream.mu.Unlock()
+
+	if prev != nil {
+		stream.RemoveConsumer(prev)
+	}
+
+	return nil
+}
+
+// Preroll return stream pre-roll buffer if exists
+func (s *Stream) Preroll() *preroll.Buffer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.preroll
+}
+
+// AddConsumerPreroll works like AddConsumer, but consumer will receive saved
+// packets for the last duration before live packets. Stream should have
+// pre-roll buffer.
+func (s *Stream) AddConsumerPreroll(cons core.Consumer, duration time.Duration) error {
+	buf := s.Preroll()
+	if buf == nil {
+		return errors.New("streams: stream without preroll")
+	}
+
+	if err := buf.AddConsumer(cons, duration); err != nil {
+		return err
+	}
+
+	s.AddInternalConsumer(cons)
+	return nil
+}
+
+// ParseDuration support Go duration format (ex. 10s, 1m) and plain seconds
+func ParseDuration(s string) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	return time.Duration(core.Atoi(s)) * time.Second
+}
@@ -28,14 +28,34 @@ type Producer struct {
 	template string
 
 	conn      core.Producer
+	medias    []*core.Media // last known medias, kept after stop
 	receivers []*core.Receiver
 	senders   []*core.Receiver
 
 	state    state
 	mu       sync.Mutex
 	workerID int
+
+	retry      retryState
+	reconnects int // total reconnects, used for statistics
+	retryMu    sync.Mutex
+
+	stallTimeout time.Duration // overrides global stall timeout if not zero
+	backoff      *Backoff      // overrides global backoff if not nil
 }
 
+type retryState struct {
+	Retry  int        `json:"retry"` // failed reconnects in a row, zero if connected
+	Error  string     `json:"error,omitempty"`
+	Next   *time.Time `json:"next,omitempty"`
+	GiveUp bool       `json:"give_up,omitempty"`
+
+	since time.Time // time of the first reconnect
+}
+
+// stallTimeout - global timeout for producers without packets, zero for disable
+var stallTimeout time.Duration
+
 const SourceTemplate = "{input}"
 
 func NewProducer(source string) *Producer {
@@ -66,6 +86,10 @@ func (p *Producer) Dial() error {
 
 		p.conn = conn
 		p.state = stateMedias
+
+		p.retryMu.Lock()
+		p.retry = retryState{}
+		p.retryMu.Unlock()
 	}
 
 	return nil
@@ -82,6 +106,18 @@ func (p *Producer) GetMedias() []*core.Media {
 	return p.conn.GetMedias()
 }
 
+// lastMedias return medias of the dialed producer or the last known medias without dialing
+func (p *Producer) lastMedias() []*core.Media {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		return p.conn.GetMedias()
+	}
+
+	return p.medias
+}
+
 func (p *Producer) GetTrack(media *core.Media, codec *core.Codec) (*core.Receiver, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -132,15 +168,76 @@ func (p *Producer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 }
 
 func (p *Producer) MarshalJSON() ([]byte, error) {
+	var b []byte
+	var err error
+
 	if conn := p.conn; conn != nil {
-		return json.Marshal(conn)
+		b, err = json.Marshal(conn)
+	} else {
+		info := map[string]string{"url": p.url}
+		b, err = json.Marshal(info)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.retryMu.Lock()
+	defer p.retryMu.Unlock()
+
+	if p.retry.Retry == 0 && !p.retry.GiveUp {
+		return b, nil
+	}
+
+	retry, err := json.Marshal(&p.retry)
+	if err != nil {
+		return nil, err
 	}
-	info := map[string]string{"url": p.url}
-	return json.Marshal(info)
+
+	return appendJSON(b, "reconnect", retry), nil
+}
+
+// appendJSON adds field to JSON object
+func appendJSON(b []byte, key string, value []byte) []byte {
+	n := len(b)
+	if n < 2 || b[n-1] != '}' {
+		return b
+	}
+	b = b[:n-1]
+	if n > 2 {
+		b = append(b, ',')
+	}
+	b = append(b, `"`+key+`":`...)
+	b = append(b, value...)
+	return append(b, '}')
 }
 
 // internals
 
+func (p *Producer) getState() state {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+// hasReaders return true if any producer track has consumers
+func (p *Producer) hasReaders() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, track := range p.receivers {
+		if len(track.Senders()) > 0 {
+			return true
+		}
+	}
+	for _, track := range p.senders {
+		if len(track.Senders()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Producer) start() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -154,25 +251,83 @@ func (p *Producer) start() {
 	p.state = stateStart
 	p.workerID++
 
+	p.Fire(&Event{Type: EventProducerOnline, URL: p.url})
+
 	go p.worker(p.conn, p.workerID)
 }
 
 func (p *Producer) worker(conn core.Producer, workerID int) {
-	if err := conn.Start(); err != nil {
-		p.mu.Lock()
-		closed := p.workerID != workerID
-		p.mu.Unlock()
+	timeout := p.stallTimeout
+	if timeout == 0 {
+		timeout = stallTimeout
+	}
+	if timeout > 0 {
+		go p.watchdog(conn, workerID, timeout)
+	}
 
-		if closed {
-			return
-		}
+	err := conn.Start()
 
+	p.mu.Lock()
+	closed := p.workerID != workerID
+	p.mu.Unlock()
+
+	if closed {
+		return
+	}
+
+	event := &Event{Type: EventProducerOffline, URL: p.url}
+	if err != nil {
 		log.Warn().Err(err).Str("url", p.url).Caller().Send()
+		event.Error = err.Error()
 	}
+	p.Fire(event)
 
 	p.reconnect(workerID, 0)
 }
 
+// watchdog stops connection if it doesn't send packets for timeout,
+// so the worker will reconnect it
+func (p *Producer) watchdog(conn core.Producer, workerID int, timeout time.Duration) {
+	var packets int
+	last := time.Now()
+
+	ticker := time.NewTicker(min(timeout, time.Second))
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		p.mu.Lock()
+		if p.workerID != workerID || p.conn != conn {
+			p.mu.Unlock()
+			return
+		}
+		var n int
+		for _, receiver := range p.receivers {
+			_, count := receiver.Stats()
+			n += count
+		}
+		empty := len(p.receivers) == 0
+		p.mu.Unlock()
+
+		// skip producers with backchannel only
+		if empty || n != packets {
+			packets = n
+			last = now
+			continue
+		}
+
+		if now.Sub(last) < timeout {
+			continue
+		}
+
+		log.Warn().Str("url", p.url).Msgf("[streams] no packets for %s, reconnect", timeout)
+
+		p.Fire(&Event{Type: EventProducerStall, URL: p.url, Error: "no packets for " + timeout.String()})
+
+		_ = conn.Stop()
+		return
+	}
+}
+
 func (p *Producer) reconnect(workerID, retry int) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -184,19 +339,54 @@ func (p *Producer) reconnect(workerID, retry int) {
 
 	log.Debug().Msgf("[streams] retry=%d to url=%s", retry, p.url)
 
+	now := time.Now()
+
+	p.retryMu.Lock()
+	if retry == 0 {
+		p.retry.since = now
+		p.reconnects++
+	}
+	p.retryMu.Unlock()
+
 	conn, err := GetProducer(p.url)
 	if err != nil {
 		log.Debug().Msgf("[streams] producer=%s", err)
 
-		timeout := time.Minute
-		if retry < 5 {
-			timeout = time.Second
-		} else if retry < 10 {
-			timeout = time.Second * 5
-		} else if retry < 20 {
-			timeout = time.Second * 10
+		backoff := p.getBackoff()
+
+		p.retryMu.Lock()
+		p.retry.Retry = retry + 1
+		p.retry.Error = err.Error()
+		p.retry.Next = nil
+
+		if backoff.GiveUp > 0 && now.Sub(p.retry.since) >= backoff.GiveUp {
+			p.retry.GiveUp = true
+			p.retryMu.Unlock()
+
+			log.Warn().Str("url", p.url).Msgf("[streams] give up reconnect after %d retries", retry+1)
+
+			p.Fire(&Event{Type: EventProducerGiveUp, URL: p.url, Retry: retry + 1, Error: err.Error()})
+
+			// new consumers will dial the source again
+			p.workerID++
+			_ = p.conn.Stop()
+			p.conn = nil
+			p.state = stateNone
+			for _, receiver := range p.receivers {
+				receiver.Close() // disconnect consumers from the dead receivers
+			}
+			p.receivers = nil
+			p.senders = nil
+			return
 		}
 
+		timeout := backoff.Delay(retry)
+		next := now.Add(timeout)
+		p.retry.Next = &next
+		p.retryMu.Unlock()
+
+		p.Fire(&Event{Type: EventProducerReconnect, URL: p.url, Retry: retry + 1, Error: err.Error()})
+
 		time.AfterFunc(timeout, func() {
 			p.reconnect(workerID, retry+1)
 		})
@@ -239,6 +429,12 @@ func (p *Producer) reconnect(workerID, retry int) {
 	// swap connections
 	p.conn = conn
 
+	p.retryMu.Lock()
+	p.retry = retryState{}
+	p.retryMu.Unlock()
+
+	p.Fire(&Event{Type: EventProducerOnline, URL: p.url, Retry: retry})
+
 	go p.worker(conn, workerID)
 }
 
@@ -260,11 +456,32 @@ func (p *Producer) stop() {
 	log.Debug().Msgf("[streams] stop producer url=%s", p.url)
 
 	if p.conn != nil {
+		p.medias = p.conn.GetMedias()
 		_ = p.conn.Stop()
 		p.conn = nil
 	}
 
+	p.Fire(&Event{Type: EventProducerStop, URL: p.url})
+
 	p.state = stateNone
 	p.receivers = nil
 	p.senders = nil
+
+	p.retryMu.Lock()
+	p.retry = retryState{}
+	p.retryMu.Unlock()
+}
+
+func (p *Producer) getBackoff() *Backoff {
+	if p.backoff != nil {
+		return p.backoff
+	}
+	return &defaultBackoff
+}
+
+// retries return failed reconnects in a row
+func (p *Producer) retries() int {
+	p.retryMu.Lock()
+	defer p.retryMu.Unlock()
+	return p.retry.Retry
 }
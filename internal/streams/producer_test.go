@@ -0,0 +1,39 @@
+package streams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStallWatchdog(t *testing.T) {
+	HandleFunc("stall", func(url string) (core.Producer, error) { return newTestProducer(), nil })
+
+	stream := NewStream(map[string]any{"url": "stall:camera", "stall_timeout": "100ms"})
+
+	stalls := make(chan *Event, 1)
+	unsubscribe := Subscribe(func(event *Event) {
+		if event.Type == EventProducerStall && event.URL == "stall:camera" {
+			stalls <- event
+		}
+	})
+	defer unsubscribe()
+
+	cons := &testConsumer{medias: []*core.Media{{
+		Kind:      core.KindVideo,
+		Direction: core.DirectionSendonly,
+		Codecs:    []*core.Codec{{Name: core.CodecH264}},
+	}}}
+	require.NoError(t, stream.AddConsumer(cons))
+
+	select {
+	case event := <-stalls:
+		require.Equal(t, "no packets for 100ms", event.Error)
+	case <-time.After(time.Second):
+		require.Fail(t, "no stall event")
+	}
+
+	stream.RemoveConsumer(cons)
+}
@@ -1,6 +1,19 @@
 package streams
 
-import "time"
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+)
+
+type publisher struct {
+	url string
+
+	retry retryState
+	mu    sync.Mutex
+}
 
 func (s *Stream) Publish(url string) error {
 	cons, run, err := GetConsumer(url)
@@ -12,16 +25,112 @@ func (s *Stream) Publish(url string) error {
 		return err
 	}
 
-	go func() {
+	pub := &publisher{url: url}
+
+	s.mu.Lock()
+	s.publishers = append(s.publishers, pub)
+	s.mu.Unlock()
+
+	go s.publish(pub, cons, run)
+
+	return nil
+}
+
+// publish runs consumer and reconnects it with backoff policy
+func (s *Stream) publish(pub *publisher, cons core.Consumer, run func()) {
+	backoff := s.getBackoff()
+
+	var retry int
+
+	for {
+		start := time.Now()
+
 		run()
 		s.RemoveConsumer(cons)
 
-		// TODO: more smart retry
-		time.Sleep(5 * time.Second)
-		_ = s.Publish(url)
-	}()
+		// start retries from the beginning if the destination worked long enough
+		if time.Since(start) >= time.Minute {
+			retry = 0
+		}
 
-	return nil
+		pub.mu.Lock()
+		pub.retry.since = time.Now()
+		pub.mu.Unlock()
+
+		for {
+			timeout := 5 * time.Second
+			if backoff.Initial > 0 {
+				timeout = backoff.Delay(retry)
+			}
+
+			now := time.Now()
+			next := now.Add(timeout)
+
+			pub.mu.Lock()
+			if backoff.GiveUp > 0 && now.Sub(pub.retry.since) >= backoff.GiveUp {
+				pub.retry.GiveUp = true
+				pub.retry.Next = nil
+				pub.mu.Unlock()
+
+				log.Warn().Str("url", pub.url).Msgf("[streams] give up publish after %d retries", retry)
+				s.fireEvent(&Event{Type: EventPublishError, URL: pub.url, Retry: retry, Error: "give up"})
+				return
+			}
+			pub.retry.Next = &next
+			pub.mu.Unlock()
+
+			time.Sleep(timeout)
+			retry++
+
+			var err error
+			if cons, run, err = GetConsumer(pub.url); err == nil {
+				if err = s.AddConsumer(cons); err == nil {
+					break
+				}
+			}
+
+			log.Debug().Err(err).Str("url", pub.url).Msg("[streams] publish")
+
+			pub.mu.Lock()
+			pub.retry.Retry = retry
+			pub.retry.Error = err.Error()
+			pub.mu.Unlock()
+
+			s.fireEvent(&Event{Type: EventPublishError, URL: pub.url, Retry: retry, Error: err.Error()})
+		}
+
+		pub.mu.Lock()
+		pub.retry = retryState{}
+		pub.mu.Unlock()
+	}
+}
+
+func (s *Stream) getBackoff() *Backoff {
+	if s.backoff != nil {
+		return s.backoff
+	}
+	return &defaultBackoff
+}
+
+func (p *publisher) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(map[string]string{"url": p.url})
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.retry.Retry == 0 && !p.retry.GiveUp {
+		return b, nil
+	}
+
+	retry, err := json.Marshal(&p.retry)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendJSON(b, "reconnect", retry), nil
 }
 
 func Publish(stream *Stream, destination any) {
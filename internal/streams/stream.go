@@ -2,20 +2,37 @@ package streams
 
 import (
 	"encoding/json"
+	"fmt"
 	"sync"
 	"sync/atomic"
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/preroll"
 )
 
 type Stream struct {
-	producers []*Producer
-	consumers []core.Consumer
-	mu        sync.Mutex
-	pending   atomic.Int32
+	name       string // first name of the stream, used for events
+	producers  []*Producer
+	consumers  []core.Consumer
+	publishers []*publisher
+	preroll    *preroll.Buffer
+	failover   *Failover
+	backoff    *Backoff  // overrides global backoff for publish if not nil
+	sent       sentStats // counters of removed consumers for metrics
+	refs       int       // users of temporary stream, guarded by streamsMu
+	mu         sync.Mutex
+	pending    atomic.Int32
 }
 
 func NewStream(source any) *Stream {
+	s := newStream(source)
+	for _, prod := range s.producers {
+		s.listenProducer(prod)
+	}
+	return s
+}
+
+func newStream(source any) *Stream {
 	switch source := source.(type) {
 	case string:
 		return &Stream{
@@ -42,7 +59,24 @@ func NewStream(source any) *Stream {
 		}
 		return s
 	case map[string]any:
-		return NewStream(source["url"])
+		s := newStream(source["url"])
+		if v, ok := source["failover"]; ok {
+			s.failover = newFailover(v)
+		}
+		if v, ok := source["stall_timeout"]; ok {
+			timeout := ParseDuration(fmt.Sprint(v))
+			for _, prod := range s.producers {
+				prod.stallTimeout = timeout
+			}
+		}
+		if v, ok := source["backoff"]; ok {
+			backoff := parseBackoff(v, defaultBackoff)
+			s.backoff = &backoff
+			for _, prod := range s.producers {
+				prod.backoff = &backoff
+			}
+		}
+		return s
 	case nil:
 		return new(Stream)
 	default:
@@ -58,6 +92,23 @@ func (s *Stream) Sources() []string {
 	return sources
 }
 
+// GetMedias return medias of running producers or the last known medias of stopped producers.
+// Producers are never dialed, failover backups are skipped.
+func (s *Stream) GetMedias() (medias []*core.Media) {
+	s.mu.Lock()
+	producers := append([]*Producer(nil), s.producers...)
+	s.mu.Unlock()
+
+	for i, prod := range producers {
+		if i > 0 && s.failover != nil && prod.getState() != stateExternal {
+			continue
+		}
+		medias = append(medias, prod.lastMedias()...)
+	}
+
+	return
+}
+
 func (s *Stream) SetSource(source string) {
 	for _, prod := range s.producers {
 		prod.SetSource(source)
@@ -71,19 +122,42 @@ func (s *Stream) RemoveConsumer(cons core.Consumer) {
 	for i, consumer := range s.consumers {
 		if consumer == cons {
 			s.consumers = append(s.consumers[:i], s.consumers[i+1:]...)
+			s.sent = s.sent.add(cons)
 			break
 		}
 	}
 	s.mu.Unlock()
 
+	s.fireEvent(&Event{Type: EventConsumerRemove, Format: consumerFormat(cons)})
+
 	s.stopProducers()
 }
 
+// removeOrphanConsumers removes all consumers if the stream doesn't have any
+// working producer, ex. all sources gave up reconnects
+func (s *Stream) removeOrphanConsumers() {
+	s.mu.Lock()
+	for _, producer := range s.producers {
+		if producer.hasReaders() {
+			s.mu.Unlock()
+			return
+		}
+	}
+	consumers := append([]core.Consumer(nil), s.consumers...)
+	s.mu.Unlock()
+
+	for _, cons := range consumers {
+		s.RemoveConsumer(cons)
+	}
+}
+
 func (s *Stream) AddProducer(prod core.Producer) {
 	producer := &Producer{conn: prod, state: stateExternal, url: "external"}
 	s.mu.Lock()
 	s.producers = append(s.producers, producer)
 	s.mu.Unlock()
+
+	s.fireEvent(&Event{Type: EventProducerOnline, URL: producer.url})
 }
 
 func (s *Stream) RemoveProducer(prod core.Producer) {
@@ -91,6 +165,9 @@ func (s *Stream) RemoveProducer(prod core.Producer) {
 	for i, producer := range s.producers {
 		if producer.conn == prod {
 			s.producers = append(s.producers[:i], s.producers[i+1:]...)
+			if producer.state == stateExternal {
+				defer s.fireEvent(&Event{Type: EventProducerOffline, URL: producer.url})
+			}
 			break
 		}
 	}
@@ -104,17 +181,9 @@ func (s *Stream) stopProducers() {
 	}
 
 	s.mu.Lock()
-producers:
 	for _, producer := range s.producers {
-		for _, track := range producer.receivers {
-			if len(track.Senders()) > 0 {
-				continue producers
-			}
-		}
-		for _, track := range producer.senders {
-			if len(track.Senders()) > 0 {
-				continue producers
-			}
+		if producer.hasReaders() || s.failover.keep(producer) {
+			continue
 		}
 		producer.stop()
 	}
@@ -125,9 +194,13 @@ func (s *Stream) MarshalJSON() ([]byte, error) {
 	var info = struct {
 		Producers []*Producer     `json:"producers"`
 		Consumers []core.Consumer `json:"consumers"`
+		Publish   []*publisher    `json:"publish,omitempty"`
+		Failover  *Failover       `json:"failover,omitempty"`
 	}{
 		Producers: s.producers,
 		Consumers: s.consumers,
+		Publish:   s.publishers,
+		Failover:  s.failover,
 	}
 	return json.Marshal(info)
 }
@@ -40,3 +40,64 @@ func TestTempate(t *testing.T) {
 	require.Equal(t, stream1, stream2)
 	require.Equal(t, "ffmpeg:rtsp://example.com#video=copy", stream1.producers[0].url)
 }
+
+func TestAcquire(t *testing.T) {
+	require.Nil(t, Acquire("temporary", false))
+
+	stream1 := Acquire("temporary", true)
+	stream2 := Acquire("temporary", true)
+	require.Equal(t, stream1, stream2)
+	defer Delete("temporary")
+
+	// first publisher finished, second still uses the stream
+	prod := newTestProducer()
+	stream2.AddProducer(prod)
+	Release("temporary", stream1)
+	require.Equal(t, stream2, Get("temporary"))
+
+	// second publisher acquired the stream, but not yet added its producer
+	stream2.RemoveProducer(prod)
+	stream3 := Acquire("temporary", true)
+	Release("temporary", stream2)
+	require.Equal(t, stream3, Get("temporary"))
+
+	Release("temporary", stream3)
+	require.Nil(t, Get("temporary"))
+
+	// streams from config are never deleted
+	stream, err := New("config")
+	require.NoError(t, err)
+	defer Delete("config")
+
+	require.Equal(t, stream, Acquire("config", true))
+	Release("config", stream)
+	require.Equal(t, stream, Get("config"))
+}
+
+func TestGetMedias(t *testing.T) {
+	var dials []string
+	HandleFunc("medias", func(url string) (core.Producer, error) {
+		dials = append(dials, url)
+		return newTestProducer(), nil
+	})
+
+	stream := NewStream(map[string]any{
+		"url":      []any{"medias:primary", "medias:backup"},
+		"failover": map[string]any{},
+	})
+
+	// sources are not dialed for medias
+	require.Nil(t, stream.GetMedias())
+	require.Nil(t, dials)
+
+	// medias of the primary source are known after the first start
+	require.NoError(t, stream.producers[0].Dial())
+	stream.producers[0].stop()
+	require.Len(t, stream.GetMedias(), 1)
+	require.Equal(t, []string{"medias:primary"}, dials)
+
+	// backup medias are skipped
+	require.NoError(t, stream.producers[1].Dial())
+	require.Len(t, stream.GetMedias(), 1)
+	stream.producers[1].stop()
+}
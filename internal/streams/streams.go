@@ -3,11 +3,15 @@ package streams
 import (
 	"errors"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/internal/api/ws"
 	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/internal/metrics"
 	"github.com/rs/zerolog"
 )
 
@@ -16,22 +20,41 @@ func Init() {
 		Streams map[string]any    `yaml:"streams"`
 		Publish map[string]any    `yaml:"publish"`
 		Preload map[string]string `yaml:"preload"`
+		Preroll map[string]string `yaml:"preroll"`
+		Sources struct {
+			StallTimeout string `yaml:"stall_timeout"`
+			Backoff      any    `yaml:"backoff"`
+		} `yaml:"sources"`
 	}
 
 	app.LoadConfig(&cfg)
 
 	log = app.GetLogger("streams")
 
+	if cfg.Sources.StallTimeout != "" {
+		stallTimeout = ParseDuration(cfg.Sources.StallTimeout)
+	}
+
+	defaultBackoff = parseBackoff(cfg.Sources.Backoff, defaultBackoff)
+
 	for name, item := range cfg.Streams {
-		streams[name] = NewStream(item)
+		stream := NewStream(item)
+		stream.name = name
+		streams[name] = stream
 	}
 
 	api.HandleFunc("api/streams", apiStreams)
 	api.HandleFunc("api/streams.dot", apiStreamsDOT)
 	api.HandleFunc("api/preload", apiPreload)
 	api.HandleFunc("api/schemes", apiSchemes)
+	api.HandleFunc("api/streams/feed", apiFeed)
 
-	if cfg.Publish == nil && cfg.Preload == nil {
+	ws.HandleFunc("streams/feed", wsFeed)
+	ws.HandleFunc("streams/feed/stop", wsFeedStop)
+
+	metrics.HandleFunc(collectMetrics)
+
+	if cfg.Publish == nil && cfg.Preload == nil && cfg.Preroll == nil {
 		return
 	}
 
@@ -47,6 +70,11 @@ func Init() {
 				log.Error().Err(err).Caller().Send()
 			}
 		}
+		for name, duration := range cfg.Preroll {
+			if err := AddPreroll(name, ParseDuration(duration)); err != nil {
+				log.Error().Err(err).Caller().Send()
+			}
+		}
 	})
 }
 
@@ -62,6 +90,7 @@ func New(name string, sources ...string) (*Stream, error) {
 	}
 
 	stream := NewStream(sources)
+	stream.name = name
 
 	streamsMu.Lock()
 	streams[name] = stream
@@ -111,6 +140,7 @@ func Patch(name string, source string) (*Stream, error) {
 
 	// create new stream with this name
 	stream := NewStream(source)
+	stream.name = name
 	streams[name] = stream
 	return stream, nil
 }
@@ -155,6 +185,77 @@ func Delete(name string) {
 	delete(streams, name)
 }
 
+// Acquire - get stream by name or create temporary stream if create is true.
+// Temporary stream is deleted after the last Release.
+func Acquire(name string, create bool) *Stream {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+
+	if stream, ok := streams[name]; ok {
+		if stream.refs > 0 {
+			stream.refs++
+		}
+		return stream
+	}
+
+	if !create {
+		return nil
+	}
+
+	log.Debug().Str("stream", name).Msg("[streams] create temporary stream")
+
+	stream := NewStream(nil)
+	stream.name = name
+	stream.refs = 1
+	streams[name] = stream
+	return stream
+}
+
+// Release - delete temporary stream, if it is no longer used and the name still points to it
+func Release(name string, stream *Stream) {
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+
+	if stream.refs == 0 {
+		return
+	}
+
+	if stream.refs--; stream.refs > 0 || streams[name] != stream {
+		return
+	}
+
+	stream.mu.Lock()
+	unused := len(stream.producers) == 0
+	stream.mu.Unlock()
+
+	if unused {
+		delete(streams, name)
+	}
+}
+
+// namedStreams return sorted streams with one name for stream with aliases
+func namedStreams() ([]*Stream, map[*Stream]string) {
+	names := map[*Stream]string{}
+
+	streamsMu.Lock()
+	for name, stream := range streams {
+		if prev, ok := names[stream]; !ok || name < prev {
+			names[stream] = name
+		}
+	}
+	streamsMu.Unlock()
+
+	items := make([]*Stream, 0, len(names))
+	for stream := range names {
+		items = append(items, stream)
+	}
+	slices.SortFunc(items, func(a, b *Stream) int {
+		return strings.Compare(names[a], names[b])
+	})
+
+	return items, names
+}
+
 func GetAllNames() []string {
 	streamsMu.Lock()
 	names := make([]string, 0, len(streams))
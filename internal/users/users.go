@@ -0,0 +1,137 @@
+package users
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"path"
+
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/rs/zerolog"
+)
+
+func Init() {
+	var cfg struct {
+		Mod map[string]*User `yaml:"users"`
+	}
+
+	app.LoadConfig(&cfg)
+
+	log = app.GetLogger("users")
+
+	for name, user := range cfg.Mod {
+		if user == nil || user.Password == "" {
+			log.Warn().Str("user", name).Msg("[users] skip user without password")
+			continue
+		}
+		user.name = name
+		users[name] = user
+	}
+}
+
+// Permissions
+const (
+	PermView        = "view"        // watch stream
+	PermPublish     = "publish"     // send stream to go2rtc
+	PermBackchannel = "backchannel" // two-way audio
+	PermPTZ         = "ptz"         // move camera and change presets
+	PermAdmin       = "admin"       // all permissions, change streams and config
+)
+
+// User - credentials and allow lists with stream name patterns
+type User struct {
+	Password    string   `yaml:"password"`
+	View        []string `yaml:"view"`
+	Publish     []string `yaml:"publish"`
+	Backchannel []string `yaml:"backchannel"`
+	PTZ         []string `yaml:"ptz"`
+	Admin       []string `yaml:"admin"`
+
+	name string
+}
+
+var log zerolog.Logger
+
+var users = map[string]*User{}
+
+// Enabled return true if users section is configured
+func Enabled() bool {
+	return len(users) > 0
+}
+
+// Auth return user for valid credentials or nil
+func Auth(username, password string) *User {
+	user := users[username]
+	if user == nil || subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+		return nil
+	}
+	return user
+}
+
+// Get return user by name, nil for unknown user
+func Get(username string) *User {
+	return users[username]
+}
+
+func (u *User) Name() string {
+	if u == nil {
+		return ""
+	}
+	return u.name
+}
+
+// Allow checks permission for the stream name.
+// Nil user has full access (authentication disabled or global credentials).
+func (u *User) Allow(perm, stream string) bool {
+	if u == nil {
+		return true
+	}
+
+	if match(u.Admin, stream) {
+		return true
+	}
+
+	switch perm {
+	case PermView:
+		return match(u.View, stream)
+	case PermPublish:
+		return match(u.Publish, stream)
+	case PermBackchannel:
+		return match(u.Backchannel, stream)
+	case PermPTZ:
+		return match(u.PTZ, stream)
+	}
+
+	return false
+}
+
+// IsAdmin return true if user has admin permission for all streams
+func (u *User) IsAdmin() bool {
+	return u == nil || match(u.Admin, "*")
+}
+
+// match stream name with patterns in path.Match syntax, e.g. "site1_*"
+func match(patterns []string, stream string) bool {
+	for _, pattern := range patterns {
+		if pattern == stream {
+			return true
+		}
+		if ok, _ := path.Match(pattern, stream); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey struct{}
+
+// WithUser return request with user in context
+func WithUser(r *http.Request, user *User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), contextKey{}, user))
+}
+
+// FromRequest return user from request context, nil for full access
+func FromRequest(r *http.Request) *User {
+	user, _ := r.Context().Value(contextKey{}).(*User)
+	return user
+}
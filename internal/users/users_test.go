@@ -0,0 +1,53 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllow(t *testing.T) {
+	user := &User{
+		View:        []string{"site1_*", "lobby"},
+		Publish:     []string{"site1_drone"},
+		Backchannel: []string{"site1_door"},
+		PTZ:         []string{"site1_cam1"},
+		Admin:       []string{"site1_test?"},
+	}
+
+	require.True(t, user.Allow(PermView, "site1_cam1"))
+	require.True(t, user.Allow(PermView, "lobby"))
+	require.False(t, user.Allow(PermView, "site2_cam1"))
+	require.False(t, user.Allow(PermView, "site1_cam/1"))
+
+	require.True(t, user.Allow(PermPublish, "site1_drone"))
+	require.False(t, user.Allow(PermPublish, "site1_cam1"))
+
+	require.True(t, user.Allow(PermBackchannel, "site1_door"))
+	require.False(t, user.Allow(PermBackchannel, "lobby"))
+
+	require.True(t, user.Allow(PermPTZ, "site1_cam1"))
+	require.False(t, user.Allow(PermPTZ, "lobby"))
+
+	// admin includes all permissions
+	require.True(t, user.Allow(PermPublish, "site1_test1"))
+	require.True(t, user.Allow(PermAdmin, "site1_test1"))
+	require.False(t, user.Allow(PermAdmin, "site1_cam1"))
+	require.False(t, user.IsAdmin())
+
+	// nil user - full access
+	user = nil
+	require.True(t, user.Allow(PermAdmin, "site2_cam1"))
+	require.True(t, user.IsAdmin())
+}
+
+func TestAuth(t *testing.T) {
+	users = map[string]*User{"user1": {Password: "pass1", name: "user1"}}
+	defer func() { users = map[string]*User{} }()
+
+	require.True(t, Enabled())
+	require.Equal(t, "user1", Auth("user1", "pass1").Name())
+	require.Nil(t, Auth("user1", "pass2"))
+	require.Nil(t, Auth("user2", "pass1"))
+	require.Nil(t, Auth("", ""))
+}
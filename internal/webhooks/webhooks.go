@@ -0,0 +1,123 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/app"
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/rs/zerolog"
+)
+
+func Init() {
+	var cfg struct {
+		Mod []*Webhook `yaml:"webhooks"`
+	}
+
+	app.LoadConfig(&cfg)
+
+	log = app.GetLogger("webhooks")
+
+	for _, hook := range cfg.Mod {
+		if hook.URL == "" {
+			continue
+		}
+		if hook.Retries == 0 {
+			hook.Retries = 3
+		}
+
+		hook.queue = make(chan *streams.Event, 100)
+		go hook.worker()
+
+		streams.Subscribe(hook.push)
+	}
+}
+
+var log zerolog.Logger
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+type Webhook struct {
+	URL     string            `yaml:"url"`
+	Events  []string          `yaml:"events"`  // empty for all events
+	Streams []string          `yaml:"streams"` // empty for all streams
+	Headers map[string]string `yaml:"headers"`
+	Retries int               `yaml:"retries"` // negative for no retries
+
+	queue chan *streams.Event
+}
+
+func (h *Webhook) match(event *streams.Event) bool {
+	if len(h.Events) > 0 && !slices.Contains(h.Events, event.Type) {
+		return false
+	}
+	if len(h.Streams) > 0 && !slices.Contains(h.Streams, event.Stream) {
+		return false
+	}
+	return true
+}
+
+// push should not block streams events dispatcher
+func (h *Webhook) push(event *streams.Event) {
+	if !h.match(event) {
+		return
+	}
+
+	select {
+	case h.queue <- event:
+	default:
+		log.Warn().Str("url", h.URL).Str("type", event.Type).Msg("[webhooks] queue is full")
+	}
+}
+
+func (h *Webhook) worker() {
+	for event := range h.queue {
+		body, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+
+		for retry := 0; ; retry++ {
+			if err = h.send(body); err == nil {
+				break
+			}
+
+			if retry >= h.Retries {
+				log.Warn().Err(err).Str("url", h.URL).Str("type", event.Type).Msg("[webhooks] send")
+				break
+			}
+
+			log.Debug().Err(err).Str("url", h.URL).Int("retry", retry+1).Msg("[webhooks] send")
+
+			time.Sleep(min(time.Second<<retry, time.Minute))
+		}
+	}
+}
+
+func (h *Webhook) send(body []byte) error {
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.New("webhooks: wrong status: " + res.Status)
+	}
+
+	return nil
+}
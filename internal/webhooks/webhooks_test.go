@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	var requests int
+	received := make(chan *streams.Event, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// first request fails and should be retried
+		if requests++; requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var event *streams.Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer server.Close()
+
+	hook := &Webhook{
+		URL:     server.URL,
+		Events:  []string{streams.EventProducerOffline},
+		Streams: []string{"camera1"},
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Retries: 1,
+		queue:   make(chan *streams.Event, 1),
+	}
+	go hook.worker()
+
+	require.False(t, hook.match(&streams.Event{Type: streams.EventProducerOnline, Stream: "camera1"}))
+	require.False(t, hook.match(&streams.Event{Type: streams.EventProducerOffline, Stream: "camera2"}))
+
+	hook.push(&streams.Event{Type: streams.EventProducerOffline, Stream: "camera1", URL: "rtsp://camera1"})
+
+	select {
+	case event := <-received:
+		require.Equal(t, "rtsp://camera1", event.URL)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "no webhook request")
+	}
+
+	require.Equal(t, 2, requests)
+}
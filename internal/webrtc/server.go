@@ -5,12 +5,11 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
-	"strconv"
 	"strings"
-	"time"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/internal/users"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/webrtc"
 	pion "github.com/pion/webrtc/v4"
@@ -18,8 +17,6 @@ import (
 
 const MimeSDP = "application/sdp"
 
-var sessions = map[string]*webrtc.Conn{}
-
 func syncHandler(w http.ResponseWriter, r *http.Request) {
 	if api.IsReadOnly() {
 		switch r.Method {
@@ -42,21 +39,9 @@ func syncHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "", http.StatusBadRequest)
 		}
 
-	case "PATCH":
-		// TODO: WHEP/WHIP
-		http.Error(w, "", http.StatusMethodNotAllowed)
-
-	case "DELETE":
-		if id := r.URL.Query().Get("id"); id != "" {
-			if conn, ok := sessions[id]; ok {
-				delete(sessions, id)
-				_ = conn.Close()
-			} else {
-				http.Error(w, "", http.StatusNotFound)
-			}
-		} else {
-			http.Error(w, "", http.StatusBadRequest)
-		}
+	case "PATCH", "DELETE":
+		// old resource URL: api/webrtc?id=...
+		sessionRequest(w, r, r.URL.Query().Get("id"))
 
 	case "OPTIONS":
 		w.WriteHeader(http.StatusNoContent)
@@ -132,7 +117,9 @@ func outputWebRTC(w http.ResponseWriter, r *http.Request) {
 		desc = "webrtc/post"
 	}
 
-	answer, err := ExchangeSDP(stream, offer, desc, r.UserAgent())
+	backchannel := users.FromRequest(r).Allow(users.PermBackchannel, u)
+
+	conn, answer, err := exchangeSDP(stream, offer, desc, r.UserAgent(), backchannel)
 	if err != nil {
 		log.Error().Err(err).Caller().Send()
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -154,6 +141,7 @@ func outputWebRTC(w http.ResponseWriter, r *http.Request) {
 		_, err = w.Write([]byte(answerB64))
 
 	case MimeSDP:
+		setSessionHeaders(w, r, addSession(conn), conn)
 		w.Header().Set("Content-Type", mediaType)
 		w.WriteHeader(http.StatusCreated)
 
@@ -217,23 +205,19 @@ func inputWebRTC(w http.ResponseWriter, r *http.Request) {
 
 	log.Trace().Msgf("[webrtc] WHIP answer\n%s", answer)
 
-	id := strconv.FormatInt(time.Now().UnixNano(), 36)
-	sessions[id] = prod
-
 	prod.Listen(func(msg any) {
 		switch msg := msg.(type) {
 		case pion.PeerConnectionState:
 			if msg == pion.PeerConnectionStateClosed {
 				stream.RemoveProducer(prod)
-				delete(sessions, id)
 			}
 		}
 	})
 
 	stream.AddProducer(prod)
 
+	setSessionHeaders(w, r, addSession(prod), prod)
 	w.Header().Set("Content-Type", MimeSDP)
-	w.Header().Set("Location", "webrtc?id="+id)
 	w.WriteHeader(http.StatusCreated)
 
 	if _, err = w.Write([]byte(answer)); err != nil {
@@ -0,0 +1,157 @@
+package webrtc
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/AlexxIT/go2rtc/internal/api"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/webrtc"
+	pion "github.com/pion/webrtc/v4"
+)
+
+// sessions - WHIP/WHEP resources
+var sessions = map[string]*webrtc.Conn{}
+var sessionsMu sync.Mutex
+
+// addSession register WHIP/WHEP resource until connection closed
+func addSession(conn *webrtc.Conn) string {
+	id := core.RandString(16, 36)
+
+	sessionsMu.Lock()
+	sessions[id] = conn
+	sessionsMu.Unlock()
+
+	conn.Listen(func(msg any) {
+		switch msg := msg.(type) {
+		case pion.PeerConnectionState:
+			if msg == pion.PeerConnectionStateClosed {
+				sessionsMu.Lock()
+				delete(sessions, id)
+				sessionsMu.Unlock()
+			}
+		}
+	})
+
+	return id
+}
+
+// setSessionHeaders - resource URL and ICE session ETag for POST response
+func setSessionHeaders(w http.ResponseWriter, r *http.Request, id string, conn *webrtc.Conn) {
+	location := r.URL.Path + "/" + id
+	if query := api.ShareQuery(r); query != "" {
+		location += "?" + query[1:] // resource for the share link
+	}
+
+	h := w.Header()
+	h.Set("Location", location)
+	h.Set("ETag", sessionETag(conn))
+	h.Set("Accept-Patch", webrtc.MimeTrickleICE)
+}
+
+// sessionETag - ICE session tag, changes after each ICE restart
+func sessionETag(conn *webrtc.Conn) string {
+	return `"` + conn.LocalUfrag() + `"`
+}
+
+// sessionHandler - WHIP/WHEP resource URL: api/webrtc/{id}
+func sessionHandler(w http.ResponseWriter, r *http.Request) {
+	if api.IsReadOnly() {
+		switch r.Method {
+		case "PATCH", "DELETE":
+			api.ReadOnlyError(w)
+			return
+		}
+	}
+
+	switch r.Method {
+	case "PATCH", "DELETE":
+		sessionRequest(w, r, path.Base(r.URL.Path))
+
+	case "OPTIONS":
+		w.Header().Set("Accept-Patch", webrtc.MimeTrickleICE)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "", http.StatusMethodNotAllowed)
+	}
+}
+
+func sessionRequest(w http.ResponseWriter, r *http.Request, id string) {
+	if id == "" {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	sessionsMu.Lock()
+	conn := sessions[id]
+	sessionsMu.Unlock()
+
+	if conn == nil {
+		http.Error(w, "", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == "DELETE" {
+		sessionsMu.Lock()
+		delete(sessions, id)
+		sessionsMu.Unlock()
+
+		_ = conn.Close()
+		return
+	}
+
+	patchSession(w, r, conn)
+}
+
+// patchSession - trickle ICE and ICE restart (RFC 8840, RFC 9725)
+func patchSession(w http.ResponseWriter, r *http.Request, conn *webrtc.Conn) {
+	mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	if strings.ToLower(strings.TrimSpace(mediaType)) != webrtc.MimeTrickleICE {
+		http.Error(w, "", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if tag := r.Header.Get("If-Match"); tag != "" && tag != "*" && tag != sessionETag(conn) {
+		http.Error(w, "", http.StatusPreconditionFailed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Trace().Msgf("[webrtc] patch sdpfrag:\n%s", body)
+
+	frag := webrtc.ParseSDPFrag(string(body))
+
+	if conn.IsICERestart(frag) {
+		local, err := conn.RestartICE(frag, GetCandidates(), FilterCandidate)
+		if err != nil {
+			log.Warn().Err(err).Caller().Send()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Trace().Msgf("[webrtc] patch answer:\n%s", local)
+
+		w.Header().Set("Content-Type", webrtc.MimeTrickleICE)
+		w.Header().Set("ETag", sessionETag(conn))
+		_, _ = w.Write([]byte(local.String()))
+		return
+	}
+
+	for _, candidate := range frag.Candidates {
+		log.Trace().Str("candidate", candidate).Msg("[webrtc] remote")
+		if err = conn.AddCandidate(candidate); err != nil {
+			log.Warn().Err(err).Caller().Send()
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
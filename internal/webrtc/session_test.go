@@ -0,0 +1,110 @@
+package webrtc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/webrtc"
+	pion "github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionICERestart(t *testing.T) {
+	api, err := webrtc.NewAPI()
+	require.Nil(t, err)
+
+	// remote WHIP/WHEP client
+	client, err := api.NewPeerConnection(pion.Configuration{})
+	require.Nil(t, err)
+	defer client.Close()
+
+	_, err = client.CreateDataChannel("test", nil)
+	require.Nil(t, err)
+
+	connected := make(chan struct{}, 2)
+	client.OnICEConnectionStateChange(func(state pion.ICEConnectionState) {
+		if state == pion.ICEConnectionStateConnected {
+			connected <- struct{}{}
+		}
+	})
+
+	offer := completeOffer(t, client, nil)
+
+	pc, err := api.NewPeerConnection(pion.Configuration{})
+	require.Nil(t, err)
+
+	conn := webrtc.NewConn(pc)
+	defer conn.Close()
+
+	id := addSession(conn)
+
+	var trickle atomic.Int32
+	conn.Listen(func(msg any) {
+		if _, ok := msg.(*pion.ICECandidate); ok {
+			trickle.Add(1)
+		}
+	})
+
+	require.Nil(t, conn.SetOffer(offer))
+	answer, err := conn.GetCompleteAnswer(nil, nil)
+	require.Nil(t, err)
+	require.Nil(t, client.SetRemoteDescription(pion.SessionDescription{Type: pion.SDPTypeAnswer, SDP: answer}))
+
+	waitConnected(t, connected)
+
+	etag := sessionETag(conn)
+
+	// ICE restart from the client with PATCH request
+	offer = completeOffer(t, client, &pion.OfferOptions{ICERestart: true})
+	frag := webrtc.ParseSDPFrag(offer)
+
+	r := httptest.NewRequest("PATCH", "/api/webrtc/"+id, strings.NewReader(frag.String()))
+	r.Header.Set("Content-Type", webrtc.MimeTrickleICE)
+	r.Header.Set("If-Match", etag)
+	w := httptest.NewRecorder()
+	sessionHandler(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	local := webrtc.ParseSDPFrag(w.Body.String())
+	require.NotEmpty(t, local.Candidates)
+
+	// connection listeners still get local candidates
+	require.NotZero(t, trickle.Load())
+
+	// apply new server credentials to the previous answer
+	oldFrag := webrtc.ParseSDPFrag(client.RemoteDescription().SDP)
+	answer = strings.ReplaceAll(answer, oldFrag.Ufrag, local.Ufrag)
+	answer = strings.ReplaceAll(answer, oldFrag.Pwd, local.Pwd)
+	require.Nil(t, client.SetRemoteDescription(pion.SessionDescription{Type: pion.SDPTypeAnswer, SDP: answer}))
+
+	for _, candidate := range local.Candidates {
+		require.Nil(t, client.AddICECandidate(pion.ICECandidateInit{Candidate: candidate}))
+	}
+
+	waitConnected(t, connected)
+}
+
+func completeOffer(t *testing.T, pc *pion.PeerConnection, options *pion.OfferOptions) string {
+	offer, err := pc.CreateOffer(options)
+	require.Nil(t, err)
+
+	gather := pion.GatheringCompletePromise(pc)
+	require.Nil(t, pc.SetLocalDescription(offer))
+	<-gather
+
+	return pc.LocalDescription().SDP
+}
+
+func waitConnected(t *testing.T, connected chan struct{}) {
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no ICE connection")
+	}
+}
@@ -3,12 +3,14 @@ package webrtc
 import (
 	"errors"
 	"net"
+	"slices"
 	"strings"
 
 	"github.com/AlexxIT/go2rtc/internal/api"
 	"github.com/AlexxIT/go2rtc/internal/api/ws"
 	"github.com/AlexxIT/go2rtc/internal/app"
 	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/internal/users"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/webrtc"
 	pion "github.com/pion/webrtc/v4"
@@ -103,9 +105,15 @@ func Init() {
 
 	// sync WebRTC server (two API versions)
 	api.HandleFunc("api/webrtc", syncHandler)
+	// WHIP/WHEP session resource: PATCH (trickle ICE, ICE restart) and DELETE
+	api.HandleFunc("api/webrtc/", sessionHandler)
 
 	// WebRTC client
 	streams.HandleFunc("webrtc", streamsHandler)
+
+	// WHIP client (publish)
+	streams.HandleConsumerFunc("webrtc", whipClient)
+	streams.HandleConsumerFunc("whip", whipClient)
 }
 
 var serverAPI, clientAPI *pion.API
@@ -204,6 +212,12 @@ func asyncHandler(tr *ws.Transport, msg *ws.Message) (err error) {
 		return err
 	}
 
+	if mode == core.ModePassiveConsumer {
+		if user := users.FromRequest(tr.Request); !user.Allow(users.PermBackchannel, query.Get("src")) {
+			stripBackchannel(conn)
+		}
+	}
+
 	switch mode {
 	case core.ModePassiveConsumer:
 		// 2. AddConsumer, so we get new tracks
@@ -240,6 +254,11 @@ func asyncHandler(tr *ws.Transport, msg *ws.Message) (err error) {
 }
 
 func ExchangeSDP(stream *streams.Stream, offer, desc, userAgent string) (answer string, err error) {
+	_, answer, err = exchangeSDP(stream, offer, desc, userAgent, true)
+	return
+}
+
+func exchangeSDP(stream *streams.Stream, offer, desc, userAgent string, backchannel bool) (conn *webrtc.Conn, answer string, err error) {
 	pc, err := PeerConnection(false)
 	if err != nil {
 		log.Error().Err(err).Caller().Send()
@@ -247,7 +266,7 @@ func ExchangeSDP(stream *streams.Stream, offer, desc, userAgent string) (answer
 	}
 
 	// create new webrtc instance
-	conn := webrtc.NewConn(pc)
+	conn = webrtc.NewConn(pc)
 	conn.FormatName = desc
 	conn.UserAgent = userAgent
 	conn.Protocol = "http"
@@ -276,6 +295,10 @@ func ExchangeSDP(stream *streams.Stream, offer, desc, userAgent string) (answer
 	if IsConsumer(conn) {
 		conn.Mode = core.ModePassiveConsumer
 
+		if !backchannel {
+			stripBackchannel(conn)
+		}
+
 		// 2. AddConsumer, so we get new tracks
 		if err = stream.AddConsumer(conn); err != nil {
 			log.Warn().Err(err).Caller().Send()
@@ -298,6 +321,13 @@ func ExchangeSDP(stream *streams.Stream, offer, desc, userAgent string) (answer
 	return
 }
 
+// stripBackchannel removes medias for sending audio from the client to the stream
+func stripBackchannel(conn *webrtc.Conn) {
+	conn.Medias = slices.DeleteFunc(conn.Medias, func(media *core.Media) bool {
+		return media.Direction == core.DirectionRecvonly
+	})
+}
+
 func IsConsumer(conn *webrtc.Conn) bool {
 	// if wants get video - consumer
 	for _, media := range conn.GetMedias() {
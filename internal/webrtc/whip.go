@@ -0,0 +1,148 @@
+package webrtc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/internal/streams"
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/webrtc"
+)
+
+// whipClient - publish stream to the WebRTC-HTTP Ingestion Protocol (WHIP) server:
+//   - webrtc:https://sfu.example.com/whip/endpoint
+//   - whip:https://sfu.example.com/whip/endpoint#token=secret
+func whipClient(rawURL string) (core.Consumer, func(), error) {
+	var query url.Values
+	if i := strings.IndexByte(rawURL, '#'); i > 0 {
+		query = streams.ParseQuery(rawURL[i+1:])
+		rawURL = rawURL[:i]
+	}
+
+	// remove webrtc: or whip:
+	_, rawURL, _ = strings.Cut(rawURL, ":")
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return nil, nil, errors.New("webrtc: unsupported publish url: " + rawURL)
+	}
+
+	pc, err := PeerConnection(true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cons := webrtc.NewConn(pc)
+	cons.FormatName = "webrtc/whip"
+	cons.Mode = core.ModeActiveConsumer
+	cons.Protocol = "http"
+	cons.URL = rawURL
+
+	location, err := whipExchange(cons, rawURL, query.Get("token"))
+	if err != nil {
+		_ = cons.Close()
+		return nil, nil, err
+	}
+
+	run := func() {
+		// wait until connection closed (disconnected, failed or stopped by stream)
+		_ = cons.Start()
+
+		// delete WHIP resource on the server
+		if err := whipRequest("DELETE", location, query.Get("token"), nil); err != nil {
+			log.Debug().Err(err).Str("url", rawURL).Msg("[webrtc] whip delete")
+		}
+	}
+
+	return cons, run, nil
+}
+
+// whipExchange send offer to the WHIP server and return the resource URL
+func whipExchange(cons *webrtc.Conn, rawURL, token string) (string, error) {
+	medias := []*core.Media{
+		{Kind: core.KindVideo, Direction: core.DirectionSendonly},
+		{Kind: core.KindAudio, Direction: core.DirectionSendonly},
+	}
+
+	offer, err := cons.CreateCompleteOffer(medias)
+	if err != nil {
+		return "", err
+	}
+
+	log.Trace().Msgf("[webrtc] whip offer:\n%s", offer)
+
+	req, err := http.NewRequest("POST", rawURL, strings.NewReader(offer))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", MimeSDP)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := http.Client{Timeout: time.Second * 5}
+	defer client.CloseIdleConnections()
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	// WHIP server must answer 201 Created, but some servers answer 200 OK
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return "", errors.New("webrtc: wrong whip response: " + res.Status)
+	}
+
+	answer, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	log.Trace().Msgf("[webrtc] whip answer:\n%s", answer)
+
+	if err = cons.SetAnswer(string(answer)); err != nil {
+		return "", err
+	}
+
+	// Location can be relative to the endpoint URL
+	var location string
+	if s := res.Header.Get("Location"); s != "" {
+		if u, err := req.URL.Parse(s); err == nil {
+			location = u.String()
+		}
+	}
+
+	return location, nil
+}
+
+func whipRequest(method, rawURL, token string, body io.Reader) error {
+	if rawURL == "" {
+		return nil // server without resource URL
+	}
+
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := http.Client{Timeout: time.Second * 5}
+	defer client.CloseIdleConnections()
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return errors.New("webrtc: wrong whip response: " + res.Status)
+	}
+
+	return nil
+}
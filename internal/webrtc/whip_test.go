@@ -0,0 +1,158 @@
+package webrtc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/webrtc"
+	"github.com/pion/rtp"
+	pion "github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhipClient(t *testing.T) {
+	api, err := webrtc.NewAPI()
+	require.Nil(t, err)
+
+	// restore the default PeerConnection for other tests
+	defaultPeerConnection := PeerConnection
+	t.Cleanup(func() { PeerConnection = defaultPeerConnection })
+
+	PeerConnection = func(active bool) (*pion.PeerConnection, error) {
+		return api.NewPeerConnection(pion.Configuration{})
+	}
+
+	deleted := make(chan string, 1)
+	received := make(chan *rtp.Packet, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case "POST":
+			if r.URL.Path != "/whip/endpoint" || r.Header.Get("Content-Type") != MimeSDP {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			offer, _ := io.ReadAll(r.Body)
+
+			pc, err := api.NewPeerConnection(pion.Configuration{})
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			t.Cleanup(func() { _ = pc.Close() })
+
+			pc.OnTrack(func(remote *pion.TrackRemote, _ *pion.RTPReceiver) {
+				for {
+					packet, _, err := remote.ReadRTP()
+					if err != nil {
+						return
+					}
+					select {
+					case received <- packet:
+					default:
+					}
+				}
+			})
+
+			answer, err := whipAnswer(pc, string(offer))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			// relative resource URL
+			w.Header().Set("Location", "resource/1")
+			w.Header().Set("Content-Type", MimeSDP)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(answer))
+
+		case "DELETE":
+			deleted <- r.URL.Path
+		}
+	}))
+	defer srv.Close()
+
+	cons, run, err := whipClient("whip:" + srv.URL + "/whip/endpoint#token=secret")
+	require.Nil(t, err)
+
+	// stream adds video track to the WHIP consumer
+	media := cons.GetMedias()[0]
+	require.Equal(t, core.KindVideo, media.Kind)
+
+	var codec *core.Codec
+	for _, c := range media.Codecs {
+		if c.Name == core.CodecH264 {
+			codec = c
+			break
+		}
+	}
+	require.NotNil(t, codec)
+
+	track := core.NewReceiver(
+		&core.Media{Kind: core.KindVideo, Direction: core.DirectionRecvonly},
+		&core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: core.PayloadTypeRAW},
+	)
+	require.Nil(t, cons.AddTrack(media, codec, track))
+
+	done := make(chan struct{})
+	go func() {
+		run()
+		close(done)
+	}()
+
+	// repeat packets until the connection is established
+	var packet *rtp.Packet
+	for i := uint32(0); packet == nil; i++ {
+		track.WriteRTP(&rtp.Packet{Header: rtp.Header{Timestamp: i * 3000}, Payload: []byte{0, 0, 0, 2, 0x65, 0x88}})
+
+		select {
+		case packet = <-received:
+		case <-time.After(10 * time.Millisecond):
+			require.Less(t, i, uint32(500), "no RTP packets")
+		}
+	}
+	require.Equal(t, []byte{0x65, 0x88}, packet.Payload)
+
+	// stream stops the consumer, client deletes WHIP resource
+	require.Nil(t, cons.Stop())
+
+	select {
+	case path := <-deleted:
+		require.Equal(t, "/whip/resource/1", path)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no DELETE request")
+	}
+
+	<-done
+}
+
+// whipAnswer - answer of the simple WHIP server with all ICE candidates
+func whipAnswer(pc *pion.PeerConnection, offer string) (string, error) {
+	desc := pion.SessionDescription{Type: pion.SDPTypeOffer, SDP: offer}
+	if err := pc.SetRemoteDescription(desc); err != nil {
+		return "", err
+	}
+
+	answer, err := pc.CreateAnswer(nil)
+	if err != nil {
+		return "", err
+	}
+
+	gathering := pion.GatheringCompletePromise(pc)
+	if err = pc.SetLocalDescription(answer); err != nil {
+		return "", err
+	}
+	<-gathering
+
+	return pc.LocalDescription().SDP, nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/AlexxIT/go2rtc/internal/isapi"
 	"github.com/AlexxIT/go2rtc/internal/ivideon"
 	"github.com/AlexxIT/go2rtc/internal/kasa"
+	"github.com/AlexxIT/go2rtc/internal/metrics"
 	"github.com/AlexxIT/go2rtc/internal/mjpeg"
 	"github.com/AlexxIT/go2rtc/internal/mp4"
 	"github.com/AlexxIT/go2rtc/internal/webp"
@@ -34,16 +35,20 @@ import (
 	"github.com/AlexxIT/go2rtc/internal/ngrok"
 	"github.com/AlexxIT/go2rtc/internal/onvif"
 	"github.com/AlexxIT/go2rtc/internal/pinggy"
+	"github.com/AlexxIT/go2rtc/internal/record"
 	"github.com/AlexxIT/go2rtc/internal/ring"
 	"github.com/AlexxIT/go2rtc/internal/roborock"
 	"github.com/AlexxIT/go2rtc/internal/rtmp"
 	"github.com/AlexxIT/go2rtc/internal/rtsp"
+	"github.com/AlexxIT/go2rtc/internal/srt"
 	"github.com/AlexxIT/go2rtc/internal/srtp"
 	"github.com/AlexxIT/go2rtc/internal/streams"
 	"github.com/AlexxIT/go2rtc/internal/tapo"
 	"github.com/AlexxIT/go2rtc/internal/tuya"
+	"github.com/AlexxIT/go2rtc/internal/users"
 	"github.com/AlexxIT/go2rtc/internal/v4l2"
 	"github.com/AlexxIT/go2rtc/internal/webrtc"
+	"github.com/AlexxIT/go2rtc/internal/webhooks"
 	"github.com/AlexxIT/go2rtc/internal/webtorrent"
 	"github.com/AlexxIT/go2rtc/internal/wyoming"
 	"github.com/AlexxIT/go2rtc/internal/wyze"
@@ -63,6 +68,7 @@ func main() {
 
 	modules := []module{
 		{"", app.Init},    // init config and logs
+		{"", users.Init},  // init users before API and servers
 		{"api", api.Init}, // init API before all others
 		{"ws", ws.Init},   // init WS API endpoint
 		{"", streams.Init},
@@ -80,6 +86,7 @@ func main() {
 		{"homekit", homekit.Init},       // homekit source, HomeKit server
 		{"onvif", onvif.Init},           // onvif source, ONVIF API server
 		{"rtmp", rtmp.Init},             // rtmp source, RTMP server
+		{"srt", srt.Init},               // srt source, SRT server
 		{"webtorrent", webtorrent.Init}, // webtorrent source, WebTorrent module
 		{"wyoming", wyoming.Init},
 		// Exec and script sources
@@ -112,8 +119,11 @@ func main() {
 		{"yandex", yandex.Init},
 		// Helper modules
 		{"debug", debug.Init},
+		{"metrics", metrics.Init},
 		{"ngrok", ngrok.Init},
 		{"pinggy", pinggy.Init},
+		{"record", record.Init},
+		{"webhooks", webhooks.Init},
 		{"srtp", srtp.Init},
 	}
 
@@ -128,6 +128,11 @@ func (c *Connection) GetSource() string {
 	return c.Source
 }
 
+// GetConnection return base connection info, used for statistics
+func (c *Connection) GetConnection() *Connection {
+	return c
+}
+
 // Create like os.Create, init Consumer with existing Transport
 func Create(w io.Writer) (*Connection, error) {
 	return &Connection{Transport: w}, nil
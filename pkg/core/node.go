@@ -44,7 +44,9 @@ func (n *Node) AppendChild(child *Node) {
 	n.childs = append(n.childs, child)
 	n.mu.Unlock()
 
+	child.mu.Lock()
 	child.parent = n
+	child.mu.Unlock()
 }
 
 func (n *Node) RemoveChild(child *Node) {
@@ -59,10 +61,18 @@ func (n *Node) RemoveChild(child *Node) {
 }
 
 func (n *Node) Close() {
-	if parent := n.parent; parent != nil {
+	n.mu.Lock()
+	parent := n.parent
+	n.mu.Unlock()
+
+	if parent != nil {
 		parent.RemoveChild(n)
 
-		if len(parent.childs) == 0 {
+		parent.mu.Lock()
+		empty := len(parent.childs) == 0
+		parent.mu.Unlock()
+
+		if empty {
 			parent.Close()
 		}
 	} else {
@@ -72,6 +82,7 @@ func (n *Node) Close() {
 	}
 }
 
+// MoveNode moves all childs from src to dst (dst can already have own childs)
 func MoveNode(dst, src *Node) {
 	src.mu.Lock()
 	childs := src.childs
@@ -79,10 +90,12 @@ func MoveNode(dst, src *Node) {
 	src.mu.Unlock()
 
 	dst.mu.Lock()
-	dst.childs = childs
+	dst.childs = append(dst.childs, childs...)
 	dst.mu.Unlock()
 
 	for _, child := range childs {
+		child.mu.Lock()
 		child.parent = dst
+		child.mu.Unlock()
 	}
 }
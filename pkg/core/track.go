@@ -27,8 +27,10 @@ func NewReceiver(media *Media, codec *Codec) *Receiver {
 		Media: media,
 	}
 	r.Input = func(packet *Packet) {
+		r.mu.Lock()
 		r.Bytes += len(packet.Payload)
 		r.Packets++
+		r.mu.Unlock()
 		for _, child := range r.childs {
 			child.Input(packet)
 		}
@@ -41,6 +43,13 @@ func (r *Receiver) WriteRTP(packet *rtp.Packet) {
 	r.Input(packet)
 }
 
+// Stats return received bytes and packets, safe for concurrent use
+func (r *Receiver) Stats() (bytes, packets int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Bytes, r.Packets
+}
+
 // Deprecated: should be removed
 func (r *Receiver) Senders() []*Sender {
 	if len(r.childs) > 0 {
@@ -97,11 +106,14 @@ func NewSender(media *Media, codec *Codec) *Sender {
 		buf:   buf,
 	}
 	s.Input = func(packet *Packet) {
+		// handler can change the payload after the packet is sent to the buffer
+		size := len(packet.Payload)
+
 		s.mu.Lock()
 		// unblock write to nil chan - OK, write to closed chan - panic
 		select {
 		case s.buf <- packet:
-			s.Bytes += len(packet.Payload)
+			s.Bytes += size
 			s.Packets++
 		default:
 			s.Drops++
@@ -164,6 +176,13 @@ func (s *Sender) State() string {
 	return "connected"
 }
 
+// Stats return sent bytes, packets and drops, safe for concurrent use
+func (s *Sender) Stats() (bytes, packets, drops int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Bytes, s.Packets, s.Drops
+}
+
 func (s *Sender) Close() {
 	// close buffer if exists
 	s.mu.Lock()
@@ -184,11 +203,10 @@ func (r *Receiver) MarshalJSON() ([]byte, error) {
 		Bytes   int      `json:"bytes,omitempty"`
 		Packets int      `json:"packets,omitempty"`
 	}{
-		ID:      r.Node.id,
-		Codec:   r.Node.Codec,
-		Bytes:   r.Bytes,
-		Packets: r.Packets,
+		ID:    r.Node.id,
+		Codec: r.Node.Codec,
 	}
+	v.Bytes, v.Packets = r.Stats()
 	for _, child := range r.childs {
 		v.Childs = append(v.Childs, child.id)
 	}
@@ -204,12 +222,10 @@ func (s *Sender) MarshalJSON() ([]byte, error) {
 		Packets int    `json:"packets,omitempty"`
 		Drops   int    `json:"drops,omitempty"`
 	}{
-		ID:      s.Node.id,
-		Codec:   s.Node.Codec,
-		Bytes:   s.Bytes,
-		Packets: s.Packets,
-		Drops:   s.Drops,
+		ID:    s.Node.id,
+		Codec: s.Node.Codec,
 	}
+	v.Bytes, v.Packets, v.Drops = s.Stats()
 	if s.parent != nil {
 		v.Parent = s.parent.id
 	}
@@ -42,9 +42,9 @@ func (w *Waiter) Done(err error) {
 	w.mu.Lock()
 
 	// safe run Done only when have tasks
-	if w.state > 0 {
+	done := w.state > 0
+	if done {
 		w.state--
-		w.WaitGroup.Done()
 	}
 
 	// block waiter for any operations after last done
@@ -53,6 +53,11 @@ func (w *Waiter) Done(err error) {
 		w.err = err
 	}
 
+	// err should be set before Wait returns
+	if done {
+		w.WaitGroup.Done()
+	}
+
 	w.mu.Unlock()
 }
 
@@ -6,6 +6,7 @@ import (
 	"github.com/AlexxIT/go2rtc/pkg/aac"
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
 	"github.com/pion/rtp"
 )
 
@@ -22,6 +23,7 @@ func NewConsumer() *Consumer {
 			Direction: core.DirectionSendonly,
 			Codecs: []*core.Codec{
 				{Name: core.CodecH264},
+				{Name: core.CodecH265}, // Enhanced RTMP
 			},
 		},
 		{
@@ -29,6 +31,7 @@ func NewConsumer() *Consumer {
 			Direction: core.DirectionSendonly,
 			Codecs: []*core.Codec{
 				{Name: core.CodecAAC},
+				{Name: core.CodecOpus}, // Enhanced RTMP
 			},
 		},
 	}
@@ -65,6 +68,22 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 			sender.Handler = h264.RepairAVCC(track.Codec, sender.Handler)
 		}
 
+	case core.CodecH265:
+		payload := c.muxer.GetPayloader(track.Codec)
+
+		sender.Handler = func(pkt *rtp.Packet) {
+			b := payload(pkt)
+			if n, err := c.wr.Write(b); err == nil {
+				c.Send += n
+			}
+		}
+
+		if track.Codec.IsRTP() {
+			sender.Handler = h265.RTPDepay(track.Codec, sender.Handler)
+		} else {
+			sender.Handler = h265.RepairAVCC(track.Codec, sender.Handler)
+		}
+
 	case core.CodecAAC:
 		payload := c.muxer.GetPayloader(track.Codec)
 
@@ -78,6 +97,16 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 		if track.Codec.IsRTP() {
 			sender.Handler = aac.RTPDepay(sender.Handler)
 		}
+
+	case core.CodecOpus:
+		payload := c.muxer.GetPayloader(track.Codec)
+
+		sender.Handler = func(pkt *rtp.Packet) {
+			b := payload(pkt)
+			if n, err := c.wr.Write(b); err == nil {
+				c.Send += n
+			}
+		}
 	}
 
 	sender.HandleRTP(track)
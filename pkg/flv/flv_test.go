@@ -1,8 +1,12 @@
 package flv
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,3 +23,68 @@ func TestTimeToRTP(t *testing.T) {
 		frameN *= 2
 	}
 }
+
+func TestEnhancedRTMP(t *testing.T) {
+	vps := []byte{0x40, 0x01, 0x0c, 0x01, 0xff, 0xff}
+	sps := []byte{0x42, 0x01, 0x01, 0x01, 0x60, 0x00, 0x00}
+	pps := []byte{0x44, 0x01, 0xc1, 0x72}
+	video := h265.ConfigToCodec(h265.EncodeConfig(vps, sps, pps))
+	audio := &core.Codec{Name: core.CodecOpus, ClockRate: 48000, Channels: 2}
+
+	muxer := &Muxer{}
+	videoPay := muxer.GetPayloader(video)
+	audioPay := muxer.GetPayloader(audio)
+
+	buf := bytes.NewBuffer(muxer.GetInit())
+
+	// AVCC with IDR NAL unit
+	b := videoPay(&rtp.Packet{Payload: []byte{0, 0, 0, 3, 0x26, 0x01, 0xaf}})
+	require.Equal(t, byte(TagVideo), b[0])
+	require.Equal(t, byte(0b1000_0000|1<<4|PacketTypeCodedFramesX), b[11])
+	require.Equal(t, FourCCHEVC, string(b[12:16]))
+	buf.Write(b)
+
+	b = audioPay(&rtp.Packet{Payload: []byte{0xfc, 0xff, 0xfe}})
+	require.Equal(t, byte(TagAudio), b[0])
+	require.Equal(t, byte(AudioExHeader<<4|PacketTypeCodedFrames), b[11])
+	require.Equal(t, FourCCOpus, string(b[12:16]))
+	buf.Write(b)
+
+	prod, err := Open(buf)
+	require.Nil(t, err)
+	require.Len(t, prod.Medias, 2)
+	require.Equal(t, video.FmtpLine, prod.Medias[0].Codecs[0].FmtpLine)
+	require.Equal(t, core.CodecOpus, prod.Medias[1].Codecs[0].Name)
+	require.Equal(t, uint8(2), prod.Medias[1].Codecs[0].Channels)
+}
+
+func TestEnhancedRTMPConfigFromKeyframe(t *testing.T) {
+	vps := []byte{0x40, 0x01, 0x0c, 0x01, 0xff, 0xff}
+	sps := []byte{0x42, 0x01, 0x01, 0x01, 0x60, 0x00, 0x00}
+	pps := []byte{0x44, 0x01, 0xc1, 0x72}
+	video := &core.Codec{Name: core.CodecH265, ClockRate: 90000}
+
+	muxer := &Muxer{}
+	videoPay := muxer.GetPayloader(video)
+
+	buf := bytes.NewBuffer(muxer.GetInit())
+
+	// P-frame before the first keyframe is useless without config
+	require.Nil(t, videoPay(&rtp.Packet{Payload: []byte{0, 0, 0, 3, 0x02, 0x01, 0xd0}}))
+
+	// AVCC keyframe with VPS, SPS, PPS and IDR NAL units
+	var avcc []byte
+	for _, nalu := range [][]byte{vps, sps, pps, {0x26, 0x01, 0xaf}} {
+		avcc = append(avcc, 0, 0, 0, byte(len(nalu)))
+		avcc = append(avcc, nalu...)
+	}
+
+	b := videoPay(&rtp.Packet{Payload: avcc})
+	require.Equal(t, byte(0b1000_0000|1<<4|PacketTypeSequenceStart), b[11])
+	buf.Write(b)
+
+	prod, err := Open(buf)
+	require.Nil(t, err)
+	require.Len(t, prod.Medias, 1)
+	require.Equal(t, h265.ConfigToCodec(h265.EncodeConfig(vps, sps, pps)).FmtpLine, prod.Medias[0].Codecs[0].FmtpLine)
+}
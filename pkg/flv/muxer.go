@@ -7,6 +7,7 @@ import (
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/flv/amf"
 	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
 	"github.com/pion/rtp"
 )
 
@@ -36,12 +37,22 @@ func (m *Muxer) GetInit() []byte {
 			b[4] |= FlagsVideo
 			obj["videocodecid"] = CodecH264
 
+		case core.CodecH265:
+			b[4] |= FlagsVideo
+			obj["videocodecid"] = fourCCToNumber(FourCCHEVC)
+
 		case core.CodecAAC:
 			b[4] |= FlagsAudio
 			obj["audiocodecid"] = CodecAAC
 			obj["audiosamplerate"] = codec.ClockRate
 			obj["audiosamplesize"] = 16
 			obj["stereo"] = codec.Channels == 2
+
+		case core.CodecOpus:
+			b[4] |= FlagsAudio
+			obj["audiocodecid"] = fourCCToNumber(FourCCOpus)
+			obj["audiosamplerate"] = codec.ClockRate
+			obj["stereo"] = codec.Channels == 2
 		}
 	}
 
@@ -65,11 +76,25 @@ func (m *Muxer) GetInit() []byte {
 			video := append(encodeAVData(codec, 0), config...)
 			b = append(b, EncodeTag(TagVideo, 0, video)...)
 
+		case core.CodecH265:
+			vps, sps, pps := h265.GetParameterSet(codec.FmtpLine)
+			if len(vps) == 0 || len(sps) == 0 || len(pps) == 0 {
+				continue // payloader will send config before the first keyframe
+			}
+
+			config := h265.EncodeConfig(vps, sps, pps)
+			video := append(encodeAVData(codec, PacketTypeSequenceStart), config...)
+			b = append(b, EncodeTag(TagVideo, 0, video)...)
+
 		case core.CodecAAC:
 			s := core.Between(codec.FmtpLine, "config=", ";")
 			config, _ := hex.DecodeString(s)
 			audio := append(encodeAVData(codec, 0), config...)
 			b = append(b, EncodeTag(TagAudio, 0, audio)...)
+
+		case core.CodecOpus:
+			audio := append(encodeAVData(codec, PacketTypeSequenceStart), opusHead(codec)...)
+			b = append(b, EncodeTag(TagAudio, 0, audio)...)
 		}
 	}
 
@@ -103,6 +128,61 @@ func (m *Muxer) GetPayloader(codec *core.Codec) func(packet *rtp.Packet) []byte
 			return EncodeTag(TagVideo, timeMS, buf)
 		}
 
+	case core.CodecH265:
+		buf := encodeAVData(codec, PacketTypeCodedFramesX)
+
+		// config from SDP will be sent with init,
+		// otherwise it will be sent from the first keyframe
+		vps, sps, pps := h265.GetParameterSet(codec.FmtpLine)
+		configOK := len(vps) > 0 && len(sps) > 0 && len(pps) > 0
+
+		return func(packet *rtp.Packet) []byte {
+			var config []byte
+
+			if h265.IsKeyframe(packet.Payload) {
+				if !configOK {
+					if vps, sps, pps = parameterSet(packet.Payload); len(vps) > 0 && len(sps) > 0 && len(pps) > 0 {
+						config = append(encodeAVData(codec, PacketTypeSequenceStart), h265.EncodeConfig(vps, sps, pps)...)
+						configOK = true
+					}
+				}
+				buf[0] = 0b1000_0000 | 1<<4 | PacketTypeCodedFramesX
+			} else {
+				buf[0] = 0b1000_0000 | 2<<4 | PacketTypeCodedFramesX
+			}
+
+			// player can't decode frames without config
+			if !configOK {
+				return nil
+			}
+
+			buf = append(buf[:5], packet.Payload...) // reset buffer to previous place
+
+			if ts0 == 0 {
+				ts0 = packet.Timestamp
+			}
+
+			timeMS := (packet.Timestamp - ts0) / k
+			if config != nil {
+				return append(EncodeTag(TagVideo, timeMS, config), EncodeTag(TagVideo, timeMS, buf)...)
+			}
+			return EncodeTag(TagVideo, timeMS, buf)
+		}
+
+	case core.CodecOpus:
+		buf := encodeAVData(codec, PacketTypeCodedFrames)
+
+		return func(packet *rtp.Packet) []byte {
+			buf = append(buf[:5], packet.Payload...)
+
+			if ts0 == 0 {
+				ts0 = packet.Timestamp
+			}
+
+			timeMS := (packet.Timestamp - ts0) / k
+			return EncodeTag(TagAudio, timeMS, buf)
+		}
+
 	case core.CodecAAC:
 		buf := encodeAVData(codec, 1)
 
@@ -149,6 +229,14 @@ func encodeAVData(codec *core.Codec, isFrame byte) []byte {
 			0, 0, 0,  // composition time = 0
 		}
 
+	case core.CodecH265:
+		// Enhanced RTMP: ex header + keyframe + packet type, FourCC
+		return append([]byte{0b1000_0000 | 1<<4 | isFrame}, FourCCHEVC...)
+
+	case core.CodecOpus:
+		// Enhanced RTMP: ex header sound format + packet type, FourCC
+		return append([]byte{AudioExHeader<<4 | isFrame}, FourCCOpus...)
+
 	case core.CodecAAC:
 		var b0 byte = 10 << 4 // AAC
 
@@ -172,3 +260,46 @@ func encodeAVData(codec *core.Codec, isFrame byte) []byte {
 
 	return nil
 }
+
+// parameterSet - returns H265 VPS, SPS and PPS from the AVCC keyframe
+func parameterSet(avcc []byte) (vps, sps, pps []byte) {
+	for len(avcc) > 4 {
+		size := 4 + int(binary.BigEndian.Uint32(avcc))
+		if size > len(avcc) {
+			break
+		}
+
+		switch h265.NALUType(avcc) {
+		case h265.NALUTypeVPS:
+			vps = avcc[4:size]
+		case h265.NALUTypeSPS:
+			sps = avcc[4:size]
+		case h265.NALUTypePPS:
+			pps = avcc[4:size]
+		}
+
+		avcc = avcc[size:]
+	}
+	return
+}
+
+// opusHead - Opus identification header (RFC 7845) for the sequence start packet
+func opusHead(codec *core.Codec) []byte {
+	channels := codec.Channels
+	if channels == 0 {
+		channels = 1
+	}
+
+	b := make([]byte, 19)
+	copy(b, "OpusHead")
+	b[8] = 1 // version
+	b[9] = channels
+	binary.LittleEndian.PutUint16(b[10:], 312)             // pre-skip
+	binary.LittleEndian.PutUint32(b[12:], codec.ClockRate) // input sample rate
+	// output gain 0, mapping family 0
+	return b
+}
+
+func fourCCToNumber(fourCC string) uint32 {
+	return binary.BigEndian.Uint32([]byte(fourCC))
+}
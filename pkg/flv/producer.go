@@ -47,6 +47,11 @@ const (
 
 	CodecH264 = 7
 	CodecHEVC = 12
+
+	AudioExHeader = 9 // Enhanced RTMP sound format
+
+	FourCCHEVC = "hvc1"
+	FourCCOpus = "Opus"
 )
 
 const (
@@ -85,12 +90,24 @@ func (c *Producer) Start() error {
 
 		switch pkt.PayloadType {
 		case TagAudio:
-			if c.audio == nil || pkt.Payload[1] == 0 {
+			if c.audio == nil {
 				continue
 			}
 
+			if pkt.Payload[0]>>4 == AudioExHeader {
+				// sound format 4b, packet type 4b, fourCC 32b
+				if len(pkt.Payload) < 5 || pkt.Payload[0]&0b1111 != PacketTypeCodedFrames {
+					continue
+				}
+				pkt.Payload = pkt.Payload[5:]
+			} else {
+				if pkt.Payload[1] == 0 {
+					continue
+				}
+				pkt.Payload = pkt.Payload[2:]
+			}
+
 			pkt.Timestamp = TimeToRTP(pkt.Timestamp, c.audio.Codec.ClockRate)
-			pkt.Payload = pkt.Payload[2:]
 			c.audio.WriteRTP(pkt)
 
 		case TagVideo:
@@ -102,9 +119,15 @@ func (c *Producer) Start() error {
 				switch packetType := pkt.Payload[0] & 0b1111; packetType {
 				case PacketTypeCodedFrames:
 					// frame type 4b, packet type 4b, fourCC 32b, composition time 24b
+					if len(pkt.Payload) < 8 {
+						continue
+					}
 					pkt.Payload = pkt.Payload[8:]
 				case PacketTypeCodedFramesX:
 					// frame type 4b, packet type 4b, fourCC 32b
+					if len(pkt.Payload) < 5 {
+						continue
+					}
 					pkt.Payload = pkt.Payload[5:]
 				default:
 					continue
@@ -172,15 +195,32 @@ func (c *Producer) probe() error {
 			_ = pkt.Payload[0] & 0b0010    // SoundSize
 			_ = pkt.Payload[0] & 0b0001    // SoundType
 
-			if codecID != CodecAAC {
-				continue
-			}
+			var codec *core.Codec
+
+			switch codecID {
+			case CodecAAC:
+				if pkt.Payload[1] != 0 { // check if header
+					continue
+				}
+
+				codec = aac.ConfigToCodec(pkt.Payload[2:])
+
+			case AudioExHeader:
+				// OpusHead: signature 8b, version 1b, channels 1b, pre-skip 2b, sample rate 4b...
+				if len(pkt.Payload) < 5+19 || string(pkt.Payload[1:5]) != FourCCOpus {
+					continue
+				}
+
+				if packetType := pkt.Payload[0] & 0b1111; packetType != PacketTypeSequenceStart {
+					continue
+				}
+
+				codec = &core.Codec{Name: core.CodecOpus, ClockRate: 48000, Channels: pkt.Payload[5+9]}
 
-			if pkt.Payload[1] != 0 { // check if header
+			default:
 				continue
 			}
 
-			codec := aac.ConfigToCodec(pkt.Payload[2:])
 			media := &core.Media{
 				Kind:      core.KindAudio,
 				Direction: core.DirectionRecvonly,
@@ -197,7 +237,7 @@ func (c *Producer) probe() error {
 			var codec *core.Codec
 
 			if isExHeader(pkt.Payload) {
-				if string(pkt.Payload[1:5]) != "hvc1" {
+				if len(pkt.Payload) < 5 || string(pkt.Payload[1:5]) != FourCCHEVC {
 					continue
 				}
 
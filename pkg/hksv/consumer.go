@@ -31,6 +31,7 @@ type HKSVConsumer struct {
 	seqNum   int
 	active   bool
 	start    bool // waiting for first keyframe
+	preroll  bool // keep packets before activation, they are replayed from pre-roll buffer
 
 	// GOP buffer - accumulate moof+mdat pairs, flush on next keyframe
 	fragBuf []byte
@@ -41,6 +42,9 @@ type HKSVConsumer struct {
 	initDone chan struct{} // closed when init is ready
 }
 
+// prebufferLength - same as PrebufferLength in the supported recording configuration
+const prebufferLength = 4 * time.Second
+
 // NewHKSVConsumer creates a new HKSV consumer that muxes H264+AAC into fMP4
 // and sends fragments over an HDS DataStream session.
 func NewHKSVConsumer(log zerolog.Logger) *HKSVConsumer {
@@ -94,7 +98,7 @@ func (c *HKSVConsumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Re
 	case core.CodecH264:
 		handler.Handler = func(packet *rtp.Packet) {
 			c.mu.Lock()
-			if !c.active {
+			if !c.active && !c.preroll {
 				c.mu.Unlock()
 				return
 			}
@@ -105,7 +109,7 @@ func (c *HKSVConsumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Re
 				}
 				c.start = true
 				c.log.Debug().Int("payloadLen", len(packet.Payload)).Msg("[hksv] first keyframe")
-			} else if h264.IsKeyframe(packet.Payload) && len(c.fragBuf) > 0 {
+			} else if h264.IsKeyframe(packet.Payload) && len(c.fragBuf) > 0 && c.active {
 				// New keyframe = flush previous GOP as one mediaFragment
 				c.flushFragment()
 			}
@@ -124,7 +128,7 @@ func (c *HKSVConsumer) AddTrack(media *core.Media, _ *core.Codec, track *core.Re
 	case core.CodecAAC:
 		handler.Handler = func(packet *rtp.Packet) {
 			c.mu.Lock()
-			if !c.active || !c.start {
+			if !c.active && !c.preroll || !c.start {
 				c.mu.Unlock()
 				return
 			}
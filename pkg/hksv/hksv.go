@@ -54,6 +54,12 @@ type StreamProvider interface {
 	RemoveConsumer(streamName string, consumer core.Consumer)
 }
 
+// PrerollProvider is an optional extension of StreamProvider. If the stream
+// has a pre-roll buffer, recording starts with footage from before the motion.
+type PrerollProvider interface {
+	AddConsumerPreroll(streamName string, consumer core.Consumer, duration time.Duration) error
+}
+
 // PairingStore persists HAP pairing data.
 type PairingStore interface {
 	SavePairings(streamName string, pairings []string) error
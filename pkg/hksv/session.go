@@ -58,6 +58,33 @@ func (hs *hksvSession) handleOpen(streamID int) error {
 		hs.stopRecording()
 	}
 
+	// Try to start with the footage from the stream pre-roll buffer
+	if provider, ok := hs.server.streams.(PrerollProvider); ok {
+		consumer := NewHKSVConsumer(hs.log)
+		consumer.preroll = true
+
+		if err := provider.AddConsumerPreroll(hs.server.stream, consumer, prebufferLength); err == nil {
+			hs.log.Debug().Str("stream", hs.server.stream).Msg("[hksv] using pre-roll buffer")
+
+			// prepared consumer is not needed anymore
+			if prepared := hs.server.takePreparedConsumer(); prepared != nil {
+				hs.server.streams.RemoveConsumer(hs.server.stream, prepared)
+				_ = prepared.Stop()
+			}
+
+			hs.consumer = consumer
+			hs.server.AddConn(consumer)
+
+			go func() {
+				if err := consumer.Activate(hs.session, streamID); err != nil {
+					hs.log.Error().Err(err).Str("stream", hs.server.stream).Msg("[hksv] activate failed")
+				}
+			}()
+
+			return nil
+		}
+	}
+
 	// Try to use the pre-started consumer from pair-verify
 	consumer := hs.server.takePreparedConsumer()
 	if consumer != nil {
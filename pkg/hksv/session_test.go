@@ -113,6 +113,55 @@ func TestSession_HandleOpen_UsesPreparedConsumer(t *testing.T) {
 	require.Nil(t, srv.takePreparedConsumer())
 }
 
+type mockPrerollProvider struct {
+	*mockStreamProvider
+	duration time.Duration
+}
+
+func (m *mockPrerollProvider) AddConsumerPreroll(streamName string, consumer core.Consumer, duration time.Duration) error {
+	m.duration = duration
+	return m.AddConsumer(streamName, consumer)
+}
+
+func TestSession_HandleOpen_UsesPreroll(t *testing.T) {
+	streams := newMockStreamProvider()
+	hs, ctrl, srv := newTestHKSVSession(t, streams)
+
+	provider := &mockPrerollProvider{mockStreamProvider: streams}
+	srv.streams = provider
+
+	prepared := NewHKSVConsumer(zerolog.Nop())
+	srv.preparedConsumer = prepared
+
+	go func() {
+		for {
+			if _, err := ctrl.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	err := hs.handleOpen(1)
+	require.NoError(t, err)
+
+	hs.mu.Lock()
+	consumer := hs.consumer
+	hs.mu.Unlock()
+
+	// new consumer should keep replayed packets until activation
+	require.NotEqual(t, prepared, consumer)
+	require.True(t, consumer.preroll)
+	require.Equal(t, prebufferLength, provider.duration)
+
+	// prepared consumer should be stopped
+	require.Nil(t, srv.takePreparedConsumer())
+	select {
+	case <-prepared.Done():
+	default:
+		t.Fatal("prepared consumer should be stopped")
+	}
+}
+
 func TestSession_HandleOpen_StreamError(t *testing.T) {
 	streams := newMockStreamProvider()
 	streams.addErr = errors.New("stream offline")
@@ -56,6 +56,10 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 
 		sender.Handler = func(pkt *rtp.Packet) {
 			b := c.muxer.GetPayload(pid, pkt.Timestamp, pkt.Payload)
+			if h264.IsKeyframe(pkt.Payload) {
+				// repeat PAT and PMT, so receivers can join in the middle (UDP multicast)
+				b = append(c.muxer.GetHeader(), b...)
+			}
 			if n, err := c.wr.Write(b); err == nil {
 				c.Send += n
 			}
@@ -72,6 +76,10 @@ func (c *Consumer) AddTrack(media *core.Media, codec *core.Codec, track *core.Re
 
 		sender.Handler = func(pkt *rtp.Packet) {
 			b := c.muxer.GetPayload(pid, pkt.Timestamp, pkt.Payload)
+			if h265.IsKeyframe(pkt.Payload) {
+				// repeat PAT and PMT, so receivers can join in the middle (UDP multicast)
+				b = append(c.muxer.GetHeader(), b...)
+			}
 			if n, err := c.wr.Write(b); err == nil {
 				c.Send += n
 			}
@@ -2,6 +2,7 @@ package mpegts
 
 import (
 	"encoding/binary"
+	"sync"
 
 	"github.com/AlexxIT/go2rtc/pkg/bits"
 	"github.com/AlexxIT/go2rtc/pkg/h264/annexb"
@@ -9,6 +10,9 @@ import (
 
 type Muxer struct {
 	pes map[uint16]*PES
+
+	counter byte // continuity counter for PAT and PMT
+	mu      sync.Mutex
 }
 
 func NewMuxer() *Muxer {
@@ -28,22 +32,32 @@ func (m *Muxer) AddTrack(streamType byte) (pid uint16) {
 		pes.StreamID = 0xC0
 	}
 
+	m.mu.Lock()
 	pid = pes0PID + uint16(len(m.pes))
 	m.pes[pid] = pes
+	m.mu.Unlock()
 
 	return
 }
 
+// GetHeader - PAT and PMT, can be repeated in the stream for the late receivers.
+// Safe to run concurrently with GetPayload and AddTrack.
 func (m *Muxer) GetHeader() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	bw := bits.NewWriter(nil)
 	m.writePAT(bw)
 	m.writePMT(bw)
+	m.counter++
 	return bw.Bytes()
 }
 
 // GetPayload - safe to run concurently with different pid
 func (m *Muxer) GetPayload(pid uint16, timestamp uint32, payload []byte) []byte {
+	m.mu.Lock()
 	pes := m.pes[pid]
+	m.mu.Unlock()
 
 	switch pes.StreamType {
 	case StreamTypeH264, StreamTypeH265:
@@ -184,10 +198,10 @@ func (m *Muxer) writeHeader(wr *bits.Writer, pid uint16) {
 	wr.WriteBit(0)          // Transport priority
 	wr.WriteBits16(pid, 13) // PID
 
-	wr.WriteBits8(0, 2) // Transport scrambling control (TSC)
-	wr.WriteBit(0)      // Adaptation field
-	wr.WriteBit(1)      // Payload
-	wr.WriteBits8(0, 4) // Continuity counter
+	wr.WriteBits8(0, 2)             // Transport scrambling control (TSC)
+	wr.WriteBit(0)                  // Adaptation field
+	wr.WriteBit(1)                  // Payload
+	wr.WriteBits8(m.counter&0xF, 4) // Continuity counter
 }
 
 func (m *Muxer) writePSIHeader(wr *bits.Writer, tableID byte, size uint16) {
@@ -0,0 +1,30 @@
+package mpegts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMuxerHeader(t *testing.T) {
+	muxer := NewMuxer()
+	pid := muxer.AddTrack(StreamTypeH264)
+
+	// header is repeated by the video sender, while consumer writes the first header
+	done := make(chan struct{})
+	go func() {
+		for range 100 {
+			_ = muxer.GetHeader()
+			_ = muxer.GetPayload(pid, 0, []byte{0, 0, 0, 2, 0x65, 0x88})
+		}
+		close(done)
+	}()
+
+	muxer.AddTrack(StreamTypeAAC)
+	for range 100 {
+		_ = muxer.GetHeader()
+	}
+	<-done
+
+	require.Equal(t, byte(200), muxer.counter)
+}
@@ -0,0 +1,226 @@
+package mpegts
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"golang.org/x/net/ipv4"
+)
+
+// DefaultPacketSize - 7 TS packets in one UDP datagram, like most of IPTV encoders
+const DefaultPacketSize = 7 * PacketSize
+
+// OpenUDP - receive MPEG-TS from the UDP port or the multicast group (FFmpeg compatible params):
+//   - udp://:1234 or udp://@:1234 - unicast, any local address
+//   - udp://239.0.0.1:1234 - multicast, join on the default interface
+//   - udp://239.0.0.1:1234?localaddr=192.168.1.10 - join on the interface with this IP
+//   - udp://239.0.0.1:1234?localaddr=eth1 - join on the interface with this name
+func OpenUDP(rawURL string) (*Producer, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := net.ResolveUDPAddr("udp4", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn *net.UDPConn
+	var protocol string
+
+	if addr.IP.IsMulticast() {
+		ifi, _, err := udpInterface(u.Query().Get("localaddr"))
+		if err != nil {
+			return nil, err
+		}
+
+		// Go sends IGMP join and enables SO_REUSEADDR, so several groups can use the same port
+		if conn, err = net.ListenMulticastUDP("udp4", ifi, addr); err != nil {
+			return nil, err
+		}
+
+		protocol = "udp+multicast"
+	} else {
+		if conn, err = net.ListenUDP("udp4", addr); err != nil {
+			return nil, err
+		}
+
+		protocol = "udp"
+	}
+
+	_ = conn.SetReadBuffer(1 << 20)
+
+	prod, err := Open(&udpReader{conn: conn, buf: make([]byte, 0xFFFF)})
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	prod.Protocol = protocol
+	prod.RemoteAddr = addr.String()
+	prod.URL = rawURL
+
+	return prod, nil
+}
+
+// DialUDP - send MPEG-TS to the UDP address or the multicast group (FFmpeg compatible params):
+//   - udp://192.168.1.123:1234 - unicast
+//   - udp://239.0.0.1:1234?ttl=4&pkt_size=1316 - multicast with TTL and datagram size
+//   - udp://239.0.0.1:1234?localaddr=eth1 - multicast from the interface with this name or IP
+func DialUDP(rawURL string) (*UDPWriter, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+
+	addr, err := net.ResolveUDPAddr("udp4", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	size := DefaultPacketSize
+	if s := query.Get("pkt_size"); s != "" {
+		// datagram should contain only whole TS packets
+		if size, _ = strconv.Atoi(s); size < PacketSize {
+			return nil, errors.New("mpegts: wrong pkt_size: " + s)
+		}
+		size -= size % PacketSize
+	}
+
+	ifi, localIP, err := udpInterface(query.Get("localaddr"))
+	if err != nil {
+		return nil, err
+	}
+
+	// not connected socket, so ICMP port unreachable from the receiver doesn't break sending
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: localIP})
+	if err != nil {
+		return nil, err
+	}
+
+	pc := ipv4.NewPacketConn(conn)
+
+	if addr.IP.IsMulticast() && ifi != nil {
+		if err = pc.SetMulticastInterface(ifi); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	if s := query.Get("ttl"); s != "" {
+		ttl, err := strconv.Atoi(s)
+		if err != nil || ttl < 0 || ttl > 255 {
+			_ = conn.Close()
+			return nil, errors.New("mpegts: wrong ttl: " + s)
+		}
+		if addr.IP.IsMulticast() {
+			err = pc.SetMulticastTTL(ttl)
+		} else {
+			err = pc.SetTTL(ttl)
+		}
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return &UDPWriter{conn: conn, addr: addr, size: size}, nil
+}
+
+type UDPWriter struct {
+	conn *net.UDPConn
+	addr *net.UDPAddr
+	size int
+}
+
+// Write - split data to datagrams with whole TS packets
+func (w *UDPWriter) Write(b []byte) (n int, err error) {
+	for len(b) > 0 {
+		size := min(len(b), w.size)
+		if _, err = w.conn.WriteToUDP(b[:size], w.addr); err != nil {
+			return
+		}
+		n += size
+		b = b[size:]
+	}
+	return
+}
+
+func (w *UDPWriter) Close() error {
+	return w.conn.Close()
+}
+
+func (w *UDPWriter) RemoteAddr() net.Addr {
+	return w.addr
+}
+
+type udpReader struct {
+	conn *net.UDPConn
+	buf  []byte
+	b    []byte // unread part of the last datagram
+}
+
+// Read - datagrams can't be read partially from the socket, so they are buffered
+func (r *udpReader) Read(p []byte) (int, error) {
+	if len(r.b) == 0 {
+		// stop producer if the source is silent
+		if err := r.conn.SetReadDeadline(time.Now().Add(core.ConnDeadline)); err != nil {
+			return 0, err
+		}
+
+		n, err := r.conn.Read(r.buf)
+		if err != nil {
+			return 0, err
+		}
+
+		r.b = r.buf[:n]
+	}
+
+	n := copy(p, r.b)
+	r.b = r.b[n:]
+	return n, nil
+}
+
+func (r *udpReader) Close() error {
+	return r.conn.Close()
+}
+
+// udpInterface - network interface and its IPv4 address by the IP or the name
+func udpInterface(localaddr string) (*net.Interface, net.IP, error) {
+	if localaddr == "" {
+		return nil, nil, nil
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ip := net.ParseIP(localaddr)
+
+	for _, ifi := range ifaces {
+		if ip == nil && ifi.Name != localaddr {
+			continue
+		}
+
+		addrs, _ := ifi.Addrs()
+		for _, addr := range addrs {
+			ipnet, ok := addr.(*net.IPNet)
+			if !ok || ipnet.IP.To4() == nil {
+				continue
+			}
+			if ip == nil || ipnet.IP.Equal(ip) {
+				return &ifi, ipnet.IP.To4(), nil
+			}
+		}
+	}
+
+	return nil, nil, errors.New("mpegts: unknown localaddr: " + localaddr)
+}
@@ -0,0 +1,92 @@
+package mpegts
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialUDP(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.Nil(t, err)
+	defer conn.Close()
+
+	// pkt_size should be rounded down to whole TS packets
+	wr, err := DialUDP("udp://" + conn.LocalAddr().String() + "?pkt_size=1000&ttl=2")
+	require.Nil(t, err)
+	defer wr.Close()
+
+	n, err := wr.Write(make([]byte, 6*PacketSize))
+	require.Nil(t, err)
+	require.Equal(t, 6*PacketSize, n)
+
+	b := make([]byte, 2000)
+	for _, size := range []int{5 * PacketSize, PacketSize} {
+		n, err = conn.Read(b)
+		require.Nil(t, err)
+		require.Equal(t, size, n)
+	}
+
+	_, err = DialUDP("udp://127.0.0.1:1234?pkt_size=100")
+	require.NotNil(t, err)
+
+	_, err = DialUDP("udp://127.0.0.1:1234?ttl=abc")
+	require.EqualError(t, err, "mpegts: wrong ttl: abc")
+}
+
+func TestOpenUDP(t *testing.T) {
+	// free local port for the producer
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.Nil(t, err)
+	addr := conn.LocalAddr().String()
+	_ = conn.Close()
+
+	wr, err := DialUDP("udp://" + addr)
+	require.Nil(t, err)
+	defer wr.Close()
+
+	muxer := NewMuxer()
+	pid := muxer.AddTrack(StreamTypeH264)
+
+	// repeat the stream until the producer starts listening
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			b := append(muxer.GetHeader(), muxer.GetPayload(pid, 0, []byte{0, 0, 0, 2, 0x65, 0x88})...)
+			_, _ = wr.Write(b)
+
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+
+	prod, err := OpenUDP("udp://" + addr)
+	require.Nil(t, err)
+	defer prod.Stop()
+
+	require.Equal(t, "udp", prod.Protocol)
+	require.Len(t, prod.Medias, 1)
+	require.Equal(t, core.CodecH264, prod.Medias[0].Codecs[0].Name)
+}
+
+func TestUDPInterface(t *testing.T) {
+	ifi, ip, err := udpInterface("127.0.0.1")
+	require.Nil(t, err)
+	require.Equal(t, net.IPv4(127, 0, 0, 1).To4(), ip)
+
+	// same interface by the name
+	_, ip, err = udpInterface(ifi.Name)
+	require.Nil(t, err)
+	require.Equal(t, net.IPv4(127, 0, 0, 1).To4(), ip)
+
+	_, _, err = udpInterface("10.255.255.255")
+	require.EqualError(t, err, "mpegts: unknown localaddr: 10.255.255.255")
+}
@@ -21,6 +21,7 @@ type Client struct {
 	mediaURL  string
 	imaginURL string
 	eventURL  string
+	ptzURL    string
 }
 
 func NewClient(rawURL string) (*Client, error) {
@@ -45,6 +46,11 @@ func NewClient(rawURL string) (*Client, error) {
 	s = FindTagValue(b, "Imaging.+?XAddr")
 	client.imaginURL = baseURL + GetPath(s, "/onvif/imaging_service")
 
+	s = FindTagValue(b, "PTZ.+?XAddr")
+	if s != "" {
+		client.ptzURL = baseURL + getURLPath(s, "/onvif/ptz_service")
+	}
+
 	s = FindTagValue(b, "Events.+?XAddr")
 	if s != "" {
 		client.eventURL = baseURL + getURLPath(s, "/onvif/event_service")
@@ -54,24 +60,13 @@ func NewClient(rawURL string) (*Client, error) {
 }
 
 func (c *Client) GetURI() (string, error) {
-	query := c.url.Query()
-
-	token := query.Get("subtype")
-
-	// support empty
-	if i := atoi(token); i >= 0 {
-		tokens, err := c.GetProfilesTokens()
-		if err != nil {
-			return "", err
-		}
-		if i >= len(tokens) {
-			return "", errors.New("onvif: wrong subtype")
-		}
-		token = tokens[i]
+	token, err := c.GetProfileToken()
+	if err != nil {
+		return "", err
 	}
 
 	getUri := c.GetStreamUri
-	if query.Has("snapshot") {
+	if c.url.Query().Has("snapshot") {
 		getUri = c.GetSnapshotUri
 	}
 
@@ -95,6 +90,25 @@ func (c *Client) GetURI() (string, error) {
 	return u.String(), nil
 }
 
+// GetProfileToken return profile token from the subtype param (token or index)
+func (c *Client) GetProfileToken() (string, error) {
+	token := c.url.Query().Get("subtype")
+
+	// support empty
+	if i := atoi(token); i >= 0 {
+		tokens, err := c.GetProfilesTokens()
+		if err != nil {
+			return "", err
+		}
+		if i >= len(tokens) {
+			return "", errors.New("onvif: wrong subtype")
+		}
+		token = tokens[i]
+	}
+
+	return token, nil
+}
+
 func (c *Client) GetName() (string, error) {
 	b, err := c.DeviceRequest(DeviceGetDeviceInformation)
 	if err != nil {
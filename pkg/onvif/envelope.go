@@ -1,10 +1,13 @@
 package onvif
 
 import (
+	"bytes"
 	"crypto/sha1"
+	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
 	"net/url"
+	"regexp"
 	"time"
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
@@ -15,7 +18,7 @@ type Envelope struct {
 }
 
 const (
-	prefix1 = `<?xml version="1.0" encoding="utf-8"?><s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:tt="http://www.onvif.org/ver10/schema" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:trt="http://www.onvif.org/ver10/media/wsdl">`
+	prefix1 = `<?xml version="1.0" encoding="utf-8"?><s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:tt="http://www.onvif.org/ver10/schema" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:trt="http://www.onvif.org/ver10/media/wsdl" xmlns:tptz="http://www.onvif.org/ver20/ptz/wsdl">`
 	prefix2 = `<s:Body>`
 	suffix  = `</s:Body></s:Envelope>`
 
@@ -117,3 +120,70 @@ func NewEventEnvelopeWithUser(user *url.Userinfo) *Envelope {
 	e.Append(prefix2)
 	return e
 }
+
+// UsernameToken - WS-Security credentials from the request header
+type UsernameToken struct {
+	Username string
+	Password string // digest or plain text password
+	Nonce    string // base64 encoded
+	Created  string
+	Digest   bool
+}
+
+var passwordTag = regexp.MustCompile(`<(?:\w+:)?Password\b[^>]*>`)
+var bodyTag = regexp.MustCompile(`<(?:\w+:)?Body\b`)
+
+// maxTokenAge - allowed clock difference between the client and the server
+const maxTokenAge = 5 * time.Minute
+
+// GetUsernameToken return WS-Security credentials from the request or nil.
+// Only Header/Security/UsernameToken is used, the Body can contain the same tags.
+func GetUsernameToken(b []byte) *UsernameToken {
+	if i := bodyTag.FindIndex(b); i != nil {
+		b = b[:i[0]]
+	}
+
+	b = findTagInner(findTagInner(findTagInner(b, "Header"), "Security"), "UsernameToken")
+
+	username := FindTagValue(b, "Username")
+	if username == "" {
+		return nil
+	}
+
+	return &UsernameToken{
+		Username: username,
+		Password: FindTagValue(b, "Password"),
+		Nonce:    FindTagValue(b, "Nonce"),
+		Created:  FindTagValue(b, "Created"),
+		Digest:   bytes.Contains(passwordTag.Find(b), []byte("#PasswordDigest")),
+	}
+}
+
+// Validate checks the password: Base64(SHA1(Nonce + Created + Password)) for the digest
+// and the creation time for protection from replay of the old requests
+func (t *UsernameToken) Validate(password string, now time.Time) bool {
+	if !t.Digest {
+		return subtle.ConstantTimeCompare([]byte(t.Password), []byte(password)) == 1
+	}
+
+	created, err := time.Parse(time.RFC3339Nano, t.Created)
+	if err != nil {
+		return false
+	}
+
+	if d := now.Sub(created); d > maxTokenAge || d < -maxTokenAge {
+		return false
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(t.Nonce)
+	if err != nil {
+		return false
+	}
+
+	h := sha1.New()
+	h.Write(nonce)
+	h.Write([]byte(t.Created + password))
+	digest := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	return subtle.ConstantTimeCompare([]byte(t.Password), []byte(digest)) == 1
+}
@@ -27,6 +27,16 @@ func FindTagValue(b []byte, tag string) string {
 	return string(m[1])
 }
 
+// findTagInner return inner XML of the first tag or nil
+func findTagInner(b []byte, tag string) []byte {
+	re := regexp.MustCompile(`(?s)<(?:\w+:)?` + tag + `\b[^>]*>(.*?)</(?:\w+:)?` + tag + `>`)
+	m := re.FindSubmatch(b)
+	if len(m) != 2 {
+		return nil
+	}
+	return m[1]
+}
+
 // UUID - generate something like 44302cbf-0d18-4feb-79b3-33b575263da3
 func UUID() string {
 	s := core.RandString(32, 16)
@@ -111,6 +121,47 @@ func DiscoveryStreamingDevices() ([]DiscoveryDevice, error) {
 	return devices, nil
 }
 
+const discoveryPrefix = `<?xml version="1.0" encoding="utf-8"?>` +
+	`<s:Envelope` +
+	` xmlns:s="http://www.w3.org/2003/05/soap-envelope"` +
+	` xmlns:a="http://schemas.xmlsoap.org/ws/2004/08/addressing"` +
+	` xmlns:d="http://schemas.xmlsoap.org/ws/2005/04/discovery"` +
+	` xmlns:dn="http://www.onvif.org/ver10/network/wsdl"` +
+	`>`
+
+// IsDiscoveryProbe return true for the WS-Discovery Probe request for ONVIF devices
+func IsDiscoveryProbe(b []byte) bool {
+	if GetRequestAction(b) != "Probe" {
+		return false
+	}
+	// empty Types - any device, also support "dn:NetworkVideoTransmitter" and "tds:Device"
+	types := FindTagValue(b, "Types")
+	return types == "" || strings.Contains(types, "NetworkVideoTransmitter") || strings.Contains(types, "Device")
+}
+
+// GetProbeMatchesResponse - WS-Discovery answer for the Probe request with device service address
+func GetProbeMatchesResponse(relatesTo, uuid, name, xaddr string) []byte {
+	e := &Envelope{buf: make([]byte, 0, 2048)}
+	e.Append(discoveryPrefix)
+	e.Appendf(`<s:Header>
+	<a:MessageID>urn:uuid:%s</a:MessageID>
+	<a:RelatesTo>%s</a:RelatesTo>
+	<a:To>http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous</a:To>
+	<a:Action>http://schemas.xmlsoap.org/ws/2005/04/discovery/ProbeMatches</a:Action>
+</s:Header>`, UUID(), relatesTo)
+	e.Append(prefix2)
+	e.Appendf(`<d:ProbeMatches>
+	<d:ProbeMatch>
+		<a:EndpointReference><a:Address>urn:uuid:%s</a:Address></a:EndpointReference>
+		<d:Types>dn:NetworkVideoTransmitter</d:Types>
+		<d:Scopes>onvif://www.onvif.org/name/%s onvif://www.onvif.org/hardware/go2rtc onvif://www.onvif.org/Profile/Streaming onvif://www.onvif.org/type/Network_Video_Transmitter</d:Scopes>
+		<d:XAddrs>%s</d:XAddrs>
+		<d:MetadataVersion>1</d:MetadataVersion>
+	</d:ProbeMatch>
+</d:ProbeMatches>`, uuid, url.PathEscape(name), xaddr)
+	return e.Bytes()
+}
+
 func findScope(s, prefix string) string {
 	s = core.Between(s, prefix, " ")
 	s, _ = url.QueryUnescape(s)
@@ -5,7 +5,9 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/stretchr/testify/require"
 )
 
@@ -225,3 +227,99 @@ func TestGetCapabilities(t *testing.T) {
 		})
 	}
 }
+
+func TestUsernameToken(t *testing.T) {
+	b := NewEnvelopeWithUser(url.UserPassword("admin", "secret")).Bytes()
+
+	token := GetUsernameToken(b)
+	require.NotNil(t, token)
+	require.Equal(t, "admin", token.Username)
+	require.True(t, token.Digest)
+
+	now := time.Now()
+	require.True(t, token.Validate("secret", now))
+	require.False(t, token.Validate("wrong", now))
+	require.False(t, token.Validate("secret", now.Add(time.Hour)))
+
+	require.Nil(t, GetUsernameToken(NewEnvelope().Bytes()))
+
+	// credentials from the body are ignored
+	b = []byte(`<s:Envelope><s:Header></s:Header><s:Body><tds:CreateUsers><tt:Username>admin</tt:Username><tt:Password>secret</tt:Password></tds:CreateUsers></s:Body></s:Envelope>`)
+	require.Nil(t, GetUsernameToken(b))
+
+	// only tags from Header/Security/UsernameToken are used
+	b = []byte(`<s:Envelope><s:Header><a:Username>admin</a:Username>` +
+		`<wsse:Security><wsse:UsernameToken><wsse:Username>user</wsse:Username><wsse:Password>pass</wsse:Password></wsse:UsernameToken></wsse:Security>` +
+		`</s:Header><s:Body><tt:Password>secret</tt:Password></s:Body></s:Envelope>`)
+	token = GetUsernameToken(b)
+	require.Equal(t, "user", token.Username)
+	require.Equal(t, "pass", token.Password)
+	require.False(t, token.Digest)
+}
+
+func TestDiscoveryProbe(t *testing.T) {
+	probe := []byte(`<?xml version="1.0" encoding="utf-8"?><s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:a="http://schemas.xmlsoap.org/ws/2004/08/addressing"><s:Header><a:Action>http://schemas.xmlsoap.org/ws/2005/04/discovery/Probe</a:Action><a:MessageID>uuid:84ede3de-7dec-11d0-c360-f01234567890</a:MessageID></s:Header><s:Body><d:Probe xmlns:d="http://schemas.xmlsoap.org/ws/2005/04/discovery"><d:Types xmlns:dn="http://www.onvif.org/ver10/network/wsdl">dn:NetworkVideoTransmitter</d:Types></d:Probe></s:Body></s:Envelope>`)
+	require.True(t, IsDiscoveryProbe(probe))
+
+	b := GetProbeMatchesResponse(FindTagValue(probe, "MessageID"), UUID(), "go2rtc", "http://192.168.1.2:1984/onvif/device_service")
+	require.Equal(t, "uuid:84ede3de-7dec-11d0-c360-f01234567890", FindTagValue(b, "RelatesTo"))
+	require.Equal(t, "http://192.168.1.2:1984/onvif/device_service", FindTagValue(b, "XAddrs"))
+	require.False(t, IsDiscoveryProbe(b))
+}
+
+func TestNewProfile(t *testing.T) {
+	medias := []*core.Media{
+		{
+			Kind: core.KindVideo, Direction: core.DirectionRecvonly,
+			Codecs: []*core.Codec{{
+				Name: core.CodecH264, ClockRate: 90000,
+				FmtpLine: "packetization-mode=1; sprop-parameter-sets=Z2QAKaw0yAeAIn5cBagICAoAAAfQAAE4gdDAAjhAACOEF3lxoYAEcIAARwgu8uFA,aO48MAA=; profile-level-id=640029",
+			}},
+		},
+		{
+			Kind: core.KindAudio, Direction: core.DirectionRecvonly,
+			Codecs: []*core.Codec{{Name: core.CodecPCMA, ClockRate: 8000}},
+		},
+		{
+			Kind: core.KindAudio, Direction: core.DirectionSendonly,
+			Codecs: []*core.Codec{{Name: core.CodecPCMA, ClockRate: 8000}},
+		},
+	}
+
+	p := NewProfile("camera1", medias)
+	require.Equal(t, "H264", p.VideoEncoding)
+	require.Equal(t, "High", p.VideoProfile)
+	require.Equal(t, uint16(1920), p.Width)
+	require.Equal(t, uint16(1080), p.Height)
+	require.Equal(t, "G711", p.AudioEncoding)
+	require.True(t, p.Backchannel)
+
+	b := GetProfileResponse(p)
+	require.Contains(t, string(b), "<tt:Encoding>G711</tt:Encoding>")
+	require.Equal(t, "camera1", FindTagValue(b, "OutputToken"))
+
+	p = NewProfile("camera2", nil)
+	require.Equal(t, "H264", p.VideoEncoding)
+	require.Equal(t, "", p.AudioEncoding)
+	require.False(t, p.Backchannel)
+}
+
+func TestPTZ(t *testing.T) {
+	b := []byte(`<s:Body><ContinuousMove xmlns="http://www.onvif.org/ver20/ptz/wsdl"><ProfileToken>camera1</ProfileToken><Velocity><PanTilt xmlns="http://www.onvif.org/ver10/schema" x="-1" y="0.5" space="http://www.onvif.org/ver10/tptz/PanTiltSpaces/VelocityGenericSpace"/><Zoom xmlns="http://www.onvif.org/ver10/schema" x="0.1"/></Velocity></ContinuousMove></s:Body>`)
+	pan, tilt, zoom := FindVelocity(b)
+	require.Equal(t, -1.0, pan)
+	require.Equal(t, 0.5, tilt)
+	require.Equal(t, 0.1, zoom)
+
+	presets := []Preset{{Token: "1", Name: "Door"}, {Token: "2", Name: "Yard & Gate"}}
+	require.Equal(t, presets, ParsePresets(GetPresetsResponse(presets)))
+}
+
+func TestCapabilitiesPTZ(t *testing.T) {
+	require.NotContains(t, string(GetCapabilitiesResponse("localhost", false)), "ptz_service")
+	require.Contains(t, string(GetCapabilitiesResponse("localhost", true)), "ptz_service")
+	require.NotContains(t, string(GetServicesResponse("localhost", false)), "ptz_service")
+	require.Contains(t, string(GetServicesResponse("localhost", true)), "ptz_service")
+
+	require.Contains(t, string(NoProfileResponse()), "ter:NoProfile")
+}
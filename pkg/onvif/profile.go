@@ -0,0 +1,105 @@
+package onvif
+
+import (
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+)
+
+// Profile - ONVIF media profile for the stream.
+// go2rtc name = ONVIF Profile Name = token for all profile configurations
+type Profile struct {
+	Name string
+
+	VideoEncoding string // H264, H265, JPEG
+	VideoProfile  string // H264 profile: Baseline, Main, Extended, High
+	Width         uint16
+	Height        uint16
+
+	AudioEncoding   string // G711, AAC, empty for stream without audio
+	AudioSampleRate uint32
+
+	Backchannel bool // stream supports two-way audio
+	PTZ         bool // stream source is ONVIF camera with PTZ service
+}
+
+// NewProfile create profile from the stream medias.
+// Stream without known medias has static H264 1920x1080 video.
+func NewProfile(name string, medias []*core.Media) *Profile {
+	p := &Profile{Name: name}
+
+	for _, media := range medias {
+		for _, codec := range media.Codecs {
+			switch {
+			case media.Kind == core.KindVideo && media.Direction == core.DirectionRecvonly:
+				if p.VideoEncoding == "" {
+					p.setVideo(codec)
+				}
+			case media.Kind == core.KindAudio && media.Direction == core.DirectionRecvonly:
+				if p.AudioEncoding == "" {
+					if p.AudioEncoding = audioEncoding(codec.Name); p.AudioEncoding != "" {
+						p.AudioSampleRate = codec.ClockRate
+					}
+				}
+			case media.Kind == core.KindAudio && media.Direction == core.DirectionSendonly:
+				p.Backchannel = true
+			}
+		}
+	}
+
+	if p.VideoEncoding == "" {
+		p.VideoEncoding = "H264"
+	}
+	if p.VideoEncoding == "H264" && p.VideoProfile == "" {
+		p.VideoProfile = "Main"
+	}
+	if p.Width == 0 || p.Height == 0 {
+		p.Width, p.Height = 1920, 1080
+	}
+
+	return p
+}
+
+func (p *Profile) setVideo(codec *core.Codec) {
+	switch codec.Name {
+	case core.CodecH264:
+		p.VideoEncoding = "H264"
+		if sps, _ := h264.GetParameterSet(codec.FmtpLine); len(sps) > 0 {
+			if s := h264.DecodeSPS(sps); s != nil {
+				p.Width, p.Height = s.Width(), s.Height()
+				switch profile := s.Profile(); profile {
+				case "Baseline", "Main", "Extended", "High":
+					p.VideoProfile = profile
+				}
+			}
+		}
+	case core.CodecH265:
+		p.VideoEncoding = "H265"
+		if _, sps, _ := h265.GetParameterSet(codec.FmtpLine); len(sps) > 2 {
+			if s := h265.DecodeSPS(sps); s != nil {
+				p.Width, p.Height = s.Width(), s.Height()
+			}
+		}
+	case core.CodecJPEG:
+		p.VideoEncoding = "JPEG"
+	}
+}
+
+// audioEncoding - ONVIF Media service supports only G711, G726 and AAC
+func audioEncoding(name string) string {
+	switch name {
+	case core.CodecPCMU, core.CodecPCMA:
+		return "G711"
+	case core.CodecAAC:
+		return "AAC"
+	}
+	return ""
+}
+
+// audioBitrate - in kbps
+func (p *Profile) audioBitrate() uint32 {
+	if p.AudioEncoding == "G711" {
+		return p.AudioSampleRate * 8 / 1000
+	}
+	return 128
+}
@@ -0,0 +1,207 @@
+package onvif
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strconv"
+)
+
+const (
+	PTZContinuousMove          = "ContinuousMove"
+	PTZGetConfigurations       = "GetConfigurations"
+	PTZGetConfigurationOptions = "GetConfigurationOptions"
+	PTZGetNodes                = "GetNodes"
+	PTZGetPresets              = "GetPresets"
+	PTZGotoPreset              = "GotoPreset"
+	PTZRemovePreset            = "RemovePreset"
+	PTZSetPreset               = "SetPreset"
+	PTZStop                    = "Stop"
+)
+
+type Preset struct {
+	Token string `json:"token"`
+	Name  string `json:"name,omitempty"`
+}
+
+func (c *Client) HasPTZ() bool {
+	return c.ptzURL != ""
+}
+
+// ContinuousMove - pan, tilt and zoom velocity in generic space from -1 to 1
+func (c *Client) ContinuousMove(token string, pan, tilt, zoom float64) error {
+	_, err := c.Request(c.ptzURL, `<tptz:ContinuousMove>
+	<tptz:ProfileToken>`+token+`</tptz:ProfileToken>
+	<tptz:Velocity>
+		<tt:PanTilt x="`+formatFloat(pan)+`" y="`+formatFloat(tilt)+`" />
+		<tt:Zoom x="`+formatFloat(zoom)+`" />
+	</tptz:Velocity>
+</tptz:ContinuousMove>`)
+	return err
+}
+
+func (c *Client) Stop(token string) error {
+	_, err := c.Request(c.ptzURL, `<tptz:Stop>
+	<tptz:ProfileToken>`+token+`</tptz:ProfileToken>
+	<tptz:PanTilt>true</tptz:PanTilt>
+	<tptz:Zoom>true</tptz:Zoom>
+</tptz:Stop>`)
+	return err
+}
+
+func (c *Client) GetPresets(token string) ([]Preset, error) {
+	b, err := c.Request(
+		c.ptzURL, `<tptz:GetPresets><tptz:ProfileToken>`+token+`</tptz:ProfileToken></tptz:GetPresets>`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePresets(b), nil
+}
+
+func (c *Client) GotoPreset(token, preset string) error {
+	_, err := c.Request(c.ptzURL, `<tptz:GotoPreset>
+	<tptz:ProfileToken>`+token+`</tptz:ProfileToken>
+	<tptz:PresetToken>`+html.EscapeString(preset)+`</tptz:PresetToken>
+</tptz:GotoPreset>`)
+	return err
+}
+
+// SetPreset save current position as the new preset and return its token
+func (c *Client) SetPreset(token, name string) (string, error) {
+	b, err := c.Request(c.ptzURL, `<tptz:SetPreset>
+	<tptz:ProfileToken>`+token+`</tptz:ProfileToken>
+	<tptz:PresetName>`+html.EscapeString(name)+`</tptz:PresetName>
+</tptz:SetPreset>`)
+	if err != nil {
+		return "", err
+	}
+	preset := FindTagValue(b, "PresetToken")
+	if preset == "" {
+		return "", errors.New("onvif: empty preset token")
+	}
+	return preset, nil
+}
+
+func (c *Client) RemovePreset(token, preset string) error {
+	_, err := c.Request(c.ptzURL, `<tptz:RemovePreset>
+	<tptz:ProfileToken>`+token+`</tptz:ProfileToken>
+	<tptz:PresetToken>`+html.EscapeString(preset)+`</tptz:PresetToken>
+</tptz:RemovePreset>`)
+	return err
+}
+
+// ParsePresets from GetPresetsResponse
+func ParsePresets(b []byte) (presets []Preset) {
+	re := regexp.MustCompile(`(?s)<(?:\w+:)?Preset\b[^>]*token="([^"]+)"[^>]*>(.*?)</(?:\w+:)?Preset>`)
+	for _, m := range re.FindAllSubmatch(b, -1) {
+		presets = append(presets, Preset{
+			Token: html.UnescapeString(string(m[1])),
+			Name:  html.UnescapeString(FindTagValue(m[2], "Name")),
+		})
+	}
+	return
+}
+
+// FindVelocity return pan, tilt and zoom from the ContinuousMove request
+func FindVelocity(b []byte) (pan, tilt, zoom float64) {
+	pan, _ = strconv.ParseFloat(findAttr(b, "PanTilt", "x"), 64)
+	tilt, _ = strconv.ParseFloat(findAttr(b, "PanTilt", "y"), 64)
+	zoom, _ = strconv.ParseFloat(findAttr(b, "Zoom", "x"), 64)
+	return
+}
+
+func findAttr(b []byte, tag, attr string) string {
+	re := regexp.MustCompile(`<(?:\w+:)?` + tag + `\b[^>]*\s` + attr + `="([^"]*)"`)
+	m := re.FindSubmatch(b)
+	if len(m) != 2 {
+		return ""
+	}
+	return string(m[1])
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func GetPTZConfigurationsResponse(profiles []*Profile) []byte {
+	e := NewEnvelope()
+	e.Append(`<tptz:GetConfigurationsResponse>`)
+	for _, profile := range profiles {
+		if profile.PTZ {
+			appendPTZConfiguration(e, "PTZConfiguration", profile)
+		}
+	}
+	e.Append(`</tptz:GetConfigurationsResponse>`)
+	return e.Bytes()
+}
+
+func appendPTZConfiguration(e *Envelope, tag string, profile *Profile) {
+	// go2rtc name = ONVIF PTZConfiguration token
+	e.Appendf(`<tt:%s token="%s">
+	<tt:Name>PTZ</tt:Name>
+	<tt:UseCount>1</tt:UseCount>
+	<tt:NodeToken>ptz</tt:NodeToken>
+	<tt:DefaultContinuousPanTiltVelocitySpace>http://www.onvif.org/ver10/tptz/PanTiltSpaces/VelocityGenericSpace</tt:DefaultContinuousPanTiltVelocitySpace>
+	<tt:DefaultContinuousZoomVelocitySpace>http://www.onvif.org/ver10/tptz/ZoomSpaces/VelocityGenericSpace</tt:DefaultContinuousZoomVelocitySpace>
+	<tt:DefaultPTZTimeout>PT5S</tt:DefaultPTZTimeout>
+</tt:%s>`, tag, profile.Name, tag)
+}
+
+func GetPresetsResponse(presets []Preset) []byte {
+	e := NewEnvelope()
+	e.Append(`<tptz:GetPresetsResponse>`)
+	for _, preset := range presets {
+		e.Appendf(
+			`<tptz:Preset token="%s"><tt:Name>%s</tt:Name></tptz:Preset>`,
+			html.EscapeString(preset.Token), html.EscapeString(preset.Name),
+		)
+	}
+	e.Append(`</tptz:GetPresetsResponse>`)
+	return e.Bytes()
+}
+
+func SetPresetResponse(preset string) []byte {
+	e := NewEnvelope()
+	e.Appendf(`<tptz:SetPresetResponse><tptz:PresetToken>%s</tptz:PresetToken></tptz:SetPresetResponse>`, html.EscapeString(preset))
+	return e.Bytes()
+}
+
+const ptzSpaces = `<tt:ContinuousPanTiltVelocitySpace>
+	<tt:URI>http://www.onvif.org/ver10/tptz/PanTiltSpaces/VelocityGenericSpace</tt:URI>
+	<tt:XRange><tt:Min>-1</tt:Min><tt:Max>1</tt:Max></tt:XRange>
+	<tt:YRange><tt:Min>-1</tt:Min><tt:Max>1</tt:Max></tt:YRange>
+</tt:ContinuousPanTiltVelocitySpace>
+<tt:ContinuousZoomVelocitySpace>
+	<tt:URI>http://www.onvif.org/ver10/tptz/ZoomSpaces/VelocityGenericSpace</tt:URI>
+	<tt:XRange><tt:Min>-1</tt:Min><tt:Max>1</tt:Max></tt:XRange>
+</tt:ContinuousZoomVelocitySpace>`
+
+var ptzResponses = map[string]string{
+	PTZContinuousMove: `<tptz:ContinuousMoveResponse />`,
+	PTZGotoPreset:     `<tptz:GotoPresetResponse />`,
+	PTZRemovePreset:   `<tptz:RemovePresetResponse />`,
+	PTZStop:           `<tptz:StopResponse />`,
+
+	PTZGetNodes: `<tptz:GetNodesResponse>
+	<tptz:PTZNode token="ptz" FixedHomePosition="false">
+		<tt:Name>PTZ</tt:Name>
+		<tt:SupportedPTZSpaces>` + ptzSpaces + `</tt:SupportedPTZSpaces>
+		<tt:MaximumNumberOfPresets>100</tt:MaximumNumberOfPresets>
+		<tt:HomeSupported>false</tt:HomeSupported>
+	</tptz:PTZNode>
+</tptz:GetNodesResponse>`,
+
+	PTZGetConfigurationOptions: `<tptz:GetConfigurationOptionsResponse>
+	<tptz:PTZConfigurationOptions>
+		<tt:Spaces>` + ptzSpaces + `</tt:Spaces>
+		<tt:PTZTimeout><tt:Min>PT1S</tt:Min><tt:Max>PT60S</tt:Max></tt:PTZTimeout>
+	</tptz:PTZConfigurationOptions>
+</tptz:GetConfigurationOptionsResponse>`,
+}
+
+func PTZStaticResponse(operation string) []byte {
+	e := NewEnvelope()
+	e.Append(ptzResponses[operation])
+	return e.Bytes()
+}
@@ -26,7 +26,10 @@ const (
 )
 
 const (
+	MediaGetAudioDecoderConfigurations       = "GetAudioDecoderConfigurations"
 	MediaGetAudioEncoderConfigurations       = "GetAudioEncoderConfigurations"
+	MediaGetAudioOutputs                     = "GetAudioOutputs"
+	MediaGetAudioOutputConfigurations        = "GetAudioOutputConfigurations"
 	MediaGetAudioSources                     = "GetAudioSources"
 	MediaGetAudioSourceConfigurations        = "GetAudioSourceConfigurations"
 	MediaGetProfile                          = "GetProfile"
@@ -55,7 +58,8 @@ func GetRequestAction(b []byte) string {
 	return string(m[1])
 }
 
-func GetCapabilitiesResponse(host string) []byte {
+// GetCapabilitiesResponse - PTZ service is advertised only if there are streams with PTZ support
+func GetCapabilitiesResponse(host string, ptz bool) []byte {
 	e := NewEnvelope()
 	e.Appendf(`<tds:GetCapabilitiesResponse>
 	<tds:Capabilities>
@@ -69,13 +73,20 @@ func GetCapabilitiesResponse(host string) []byte {
 				<tt:RTP_TCP>false</tt:RTP_TCP>
 				<tt:RTP_RTSP_TCP>true</tt:RTP_RTSP_TCP>
 			</tt:StreamingCapabilities>
-		</tt:Media>
+		</tt:Media>`, host, host)
+	if ptz {
+		e.Appendf(`
+		<tt:PTZ>
+			<tt:XAddr>http://%s/onvif/ptz_service</tt:XAddr>
+		</tt:PTZ>`, host)
+	}
+	e.Append(`
 	</tds:Capabilities>
-</tds:GetCapabilitiesResponse>`, host, host)
+</tds:GetCapabilitiesResponse>`)
 	return e.Bytes()
 }
 
-func GetServicesResponse(host string) []byte {
+func GetServicesResponse(host string, ptz bool) []byte {
 	e := NewEnvelope()
 	e.Appendf(`<tds:GetServicesResponse>
 	<tds:Service>
@@ -87,8 +98,17 @@ func GetServicesResponse(host string) []byte {
 		<tds:Namespace>http://www.onvif.org/ver10/media/wsdl</tds:Namespace>
 		<tds:XAddr>http://%s/onvif/media_service</tds:XAddr>
 		<tds:Version><tt:Major>2</tt:Major><tt:Minor>5</tt:Minor></tds:Version>
-	</tds:Service>
-</tds:GetServicesResponse>`, host, host)
+	</tds:Service>`, host, host)
+	if ptz {
+		e.Appendf(`
+	<tds:Service>
+		<tds:Namespace>http://www.onvif.org/ver20/ptz/wsdl</tds:Namespace>
+		<tds:XAddr>http://%s/onvif/ptz_service</tds:XAddr>
+		<tds:Version><tt:Major>2</tt:Major><tt:Minor>5</tt:Minor></tds:Version>
+	</tds:Service>`, host)
+	}
+	e.Append(`
+</tds:GetServicesResponse>`)
 	return e.Bytes()
 }
 
@@ -133,102 +153,232 @@ func GetDeviceInformationResponse(manuf, model, firmware, serial string) []byte
 	return e.Bytes()
 }
 
-func GetProfilesResponse(names []string) []byte {
+func GetProfilesResponse(profiles []*Profile) []byte {
 	e := NewEnvelope()
 	e.Append(`<trt:GetProfilesResponse>`)
-	for _, name := range names {
-		appendProfile(e, "Profiles", name)
+	for _, profile := range profiles {
+		appendProfile(e, "Profiles", profile)
 	}
 	e.Append(`</trt:GetProfilesResponse>`)
 	return e.Bytes()
 }
 
-func GetProfileResponse(name string) []byte {
+func GetProfileResponse(profile *Profile) []byte {
 	e := NewEnvelope()
 	e.Append(`<trt:GetProfileResponse>`)
-	appendProfile(e, "Profile", name)
+	appendProfile(e, "Profile", profile)
 	e.Append(`</trt:GetProfileResponse>`)
 	return e.Bytes()
 }
 
-func appendProfile(e *Envelope, tag, name string) {
+func appendProfile(e *Envelope, tag string, profile *Profile) {
 	// go2rtc name = ONVIF Profile Name = ONVIF Profile token
-	e.Appendf(`<trt:%s token="%s" fixed="true">`, tag, name)
-	e.Appendf(`<tt:Name>%s</tt:Name>`, name)
-	appendVideoSourceConfiguration(e, "VideoSourceConfiguration", name)
-	appendVideoEncoderConfiguration(e, "VideoEncoderConfiguration")
+	e.Appendf(`<trt:%s token="%s" fixed="true">`, tag, profile.Name)
+	e.Appendf(`<tt:Name>%s</tt:Name>`, profile.Name)
+	appendVideoSourceConfiguration(e, "VideoSourceConfiguration", profile)
+	if profile.AudioEncoding != "" {
+		appendAudioSourceConfiguration(e, "AudioSourceConfiguration", profile)
+	}
+	appendVideoEncoderConfiguration(e, "VideoEncoderConfiguration", profile)
+	if profile.AudioEncoding != "" {
+		appendAudioEncoderConfiguration(e, "AudioEncoderConfiguration", profile)
+	}
+	if profile.PTZ {
+		appendPTZConfiguration(e, "PTZConfiguration", profile)
+	}
+	if profile.Backchannel {
+		e.Append(`<tt:Extension>`)
+		appendAudioOutputConfiguration(e, "AudioOutputConfiguration", profile)
+		appendAudioDecoderConfiguration(e, "AudioDecoderConfiguration", profile)
+		e.Append(`</tt:Extension>`)
+	}
 	e.Appendf(`</trt:%s>`, tag)
 }
 
-func GetVideoSourcesResponse(names []string) []byte {
+func GetVideoSourcesResponse(profiles []*Profile) []byte {
 	// go2rtc name = ONVIF VideoSource token
 	e := NewEnvelope()
 	e.Append(`<trt:GetVideoSourcesResponse>`)
-	for _, name := range names {
+	for _, profile := range profiles {
 		e.Appendf(`<trt:VideoSources token="%s">
 	<tt:Framerate>30.000000</tt:Framerate>
-	<tt:Resolution><tt:Width>1920</tt:Width><tt:Height>1080</tt:Height></tt:Resolution>
-</trt:VideoSources>`, name)
+	<tt:Resolution><tt:Width>%d</tt:Width><tt:Height>%d</tt:Height></tt:Resolution>
+</trt:VideoSources>`, profile.Name, profile.Width, profile.Height)
 	}
 	e.Append(`</trt:GetVideoSourcesResponse>`)
 	return e.Bytes()
 }
 
-func GetVideoSourceConfigurationsResponse(names []string) []byte {
+func GetVideoSourceConfigurationsResponse(profiles []*Profile) []byte {
 	e := NewEnvelope()
 	e.Append(`<trt:GetVideoSourceConfigurationsResponse>`)
-	for _, name := range names {
-		appendVideoSourceConfiguration(e, "Configurations", name)
+	for _, profile := range profiles {
+		appendVideoSourceConfiguration(e, "Configurations", profile)
 	}
 	e.Append(`</trt:GetVideoSourceConfigurationsResponse>`)
 	return e.Bytes()
 }
 
-func GetVideoSourceConfigurationResponse(name string) []byte {
+func GetVideoSourceConfigurationResponse(profile *Profile) []byte {
 	e := NewEnvelope()
 	e.Append(`<trt:GetVideoSourceConfigurationResponse>`)
-	appendVideoSourceConfiguration(e, "Configuration", name)
+	appendVideoSourceConfiguration(e, "Configuration", profile)
 	e.Append(`</trt:GetVideoSourceConfigurationResponse>`)
 	return e.Bytes()
 }
 
-func appendVideoSourceConfiguration(e *Envelope, tag, name string) {
+func appendVideoSourceConfiguration(e *Envelope, tag string, profile *Profile) {
 	// go2rtc name = ONVIF VideoSourceConfiguration token
 	e.Appendf(`<tt:%s token="%s" fixed="true">
 	<tt:Name>VSC</tt:Name>
 	<tt:SourceToken>%s</tt:SourceToken>
-	<tt:Bounds x="0" y="0" width="1920" height="1080"></tt:Bounds>
-</tt:%s>`, tag, name, name, tag)
+	<tt:Bounds x="0" y="0" width="%d" height="%d"></tt:Bounds>
+</tt:%s>`, tag, profile.Name, profile.Name, profile.Width, profile.Height, tag)
 }
 
-func GetVideoEncoderConfigurationsResponse() []byte {
+func GetVideoEncoderConfigurationsResponse(profiles []*Profile) []byte {
 	e := NewEnvelope()
 	e.Append(`<trt:GetVideoEncoderConfigurationsResponse>`)
-	appendVideoEncoderConfiguration(e, "VideoEncoderConfigurations")
+	for _, profile := range profiles {
+		appendVideoEncoderConfiguration(e, "VideoEncoderConfigurations", profile)
+	}
 	e.Append(`</trt:GetVideoEncoderConfigurationsResponse>`)
 	return e.Bytes()
 }
 
-func GetVideoEncoderConfigurationResponse() []byte {
+func GetVideoEncoderConfigurationResponse(profile *Profile) []byte {
 	e := NewEnvelope()
 	e.Append(`<trt:GetVideoEncoderConfigurationResponse>`)
-	appendVideoEncoderConfiguration(e, "VideoEncoderConfiguration")
+	appendVideoEncoderConfiguration(e, "VideoEncoderConfiguration", profile)
 	e.Append(`</trt:GetVideoEncoderConfigurationResponse>`)
 	return e.Bytes()
 }
 
-func appendVideoEncoderConfiguration(e *Envelope, tag string) {
+func appendVideoEncoderConfiguration(e *Envelope, tag string, profile *Profile) {
 	// empty `RateControl` important for UniFi Protect
-	e.Appendf(`<tt:%s token="vec">
+	e.Appendf(`<tt:%s token="%s">
 		<tt:Name>VEC</tt:Name>
         <tt:UseCount>1</tt:UseCount>
-		<tt:Encoding>H264</tt:Encoding>
-		<tt:Resolution><tt:Width>1920</tt:Width><tt:Height>1080</tt:Height></tt:Resolution>
+		<tt:Encoding>%s</tt:Encoding>
+		<tt:Resolution><tt:Width>%d</tt:Width><tt:Height>%d</tt:Height></tt:Resolution>
         <tt:Quality>0</tt:Quality>
-		<tt:RateControl><tt:FrameRateLimit>30</tt:FrameRateLimit><tt:EncodingInterval>1</tt:EncodingInterval><tt:BitrateLimit>8192</tt:BitrateLimit></tt:RateControl>
-        <tt:H264><tt:GovLength>10</tt:GovLength><tt:H264Profile>Main</tt:H264Profile></tt:H264>
+		<tt:RateControl><tt:FrameRateLimit>30</tt:FrameRateLimit><tt:EncodingInterval>1</tt:EncodingInterval><tt:BitrateLimit>8192</tt:BitrateLimit></tt:RateControl>`,
+		tag, profile.Name, profile.VideoEncoding, profile.Width, profile.Height)
+	if profile.VideoEncoding == "H264" {
+		e.Appendf(`
+        <tt:H264><tt:GovLength>10</tt:GovLength><tt:H264Profile>%s</tt:H264Profile></tt:H264>`, profile.VideoProfile)
+	}
+	e.Appendf(`
         <tt:SessionTimeout>PT10S</tt:SessionTimeout>
-	</tt:%s>`, tag, tag)
+	</tt:%s>`, tag)
+}
+
+func GetAudioSourcesResponse(profiles []*Profile) []byte {
+	// go2rtc name = ONVIF AudioSource token
+	e := NewEnvelope()
+	e.Append(`<trt:GetAudioSourcesResponse>`)
+	for _, profile := range profiles {
+		if profile.AudioEncoding != "" {
+			e.Appendf(`<trt:AudioSources token="%s"><tt:Channels>1</tt:Channels></trt:AudioSources>`, profile.Name)
+		}
+	}
+	e.Append(`</trt:GetAudioSourcesResponse>`)
+	return e.Bytes()
+}
+
+func GetAudioSourceConfigurationsResponse(profiles []*Profile) []byte {
+	e := NewEnvelope()
+	e.Append(`<trt:GetAudioSourceConfigurationsResponse>`)
+	for _, profile := range profiles {
+		if profile.AudioEncoding != "" {
+			appendAudioSourceConfiguration(e, "Configurations", profile)
+		}
+	}
+	e.Append(`</trt:GetAudioSourceConfigurationsResponse>`)
+	return e.Bytes()
+}
+
+func appendAudioSourceConfiguration(e *Envelope, tag string, profile *Profile) {
+	e.Appendf(`<tt:%s token="%s">
+	<tt:Name>ASC</tt:Name>
+	<tt:UseCount>1</tt:UseCount>
+	<tt:SourceToken>%s</tt:SourceToken>
+</tt:%s>`, tag, profile.Name, profile.Name, tag)
+}
+
+func GetAudioEncoderConfigurationsResponse(profiles []*Profile) []byte {
+	e := NewEnvelope()
+	e.Append(`<trt:GetAudioEncoderConfigurationsResponse>`)
+	for _, profile := range profiles {
+		if profile.AudioEncoding != "" {
+			appendAudioEncoderConfiguration(e, "Configurations", profile)
+		}
+	}
+	e.Append(`</trt:GetAudioEncoderConfigurationsResponse>`)
+	return e.Bytes()
+}
+
+func appendAudioEncoderConfiguration(e *Envelope, tag string, profile *Profile) {
+	// SampleRate in kHz, Bitrate in kbps
+	e.Appendf(`<tt:%s token="%s">
+	<tt:Name>AEC</tt:Name>
+	<tt:UseCount>1</tt:UseCount>
+	<tt:Encoding>%s</tt:Encoding>
+	<tt:Bitrate>%d</tt:Bitrate>
+	<tt:SampleRate>%d</tt:SampleRate>
+	<tt:SessionTimeout>PT10S</tt:SessionTimeout>
+</tt:%s>`, tag, profile.Name, profile.AudioEncoding, profile.audioBitrate(), profile.AudioSampleRate/1000, tag)
+}
+
+func GetAudioOutputsResponse(profiles []*Profile) []byte {
+	// go2rtc name = ONVIF AudioOutput token
+	e := NewEnvelope()
+	e.Append(`<trt:GetAudioOutputsResponse>`)
+	for _, profile := range profiles {
+		if profile.Backchannel {
+			e.Appendf(`<trt:AudioOutputs token="%s" />`, profile.Name)
+		}
+	}
+	e.Append(`</trt:GetAudioOutputsResponse>`)
+	return e.Bytes()
+}
+
+func GetAudioOutputConfigurationsResponse(profiles []*Profile) []byte {
+	e := NewEnvelope()
+	e.Append(`<trt:GetAudioOutputConfigurationsResponse>`)
+	for _, profile := range profiles {
+		if profile.Backchannel {
+			appendAudioOutputConfiguration(e, "Configurations", profile)
+		}
+	}
+	e.Append(`</trt:GetAudioOutputConfigurationsResponse>`)
+	return e.Bytes()
+}
+
+func appendAudioOutputConfiguration(e *Envelope, tag string, profile *Profile) {
+	e.Appendf(`<tt:%s token="%s">
+	<tt:Name>AOC</tt:Name>
+	<tt:UseCount>1</tt:UseCount>
+	<tt:OutputToken>%s</tt:OutputToken>
+	<tt:SendPrimacy>www.onvif.org/ver20/HalfDuplex/Auto</tt:SendPrimacy>
+	<tt:OutputLevel>100</tt:OutputLevel>
+</tt:%s>`, tag, profile.Name, profile.Name, tag)
+}
+
+func GetAudioDecoderConfigurationsResponse(profiles []*Profile) []byte {
+	e := NewEnvelope()
+	e.Append(`<trt:GetAudioDecoderConfigurationsResponse>`)
+	for _, profile := range profiles {
+		if profile.Backchannel {
+			appendAudioDecoderConfiguration(e, "Configurations", profile)
+		}
+	}
+	e.Append(`</trt:GetAudioDecoderConfigurationsResponse>`)
+	return e.Bytes()
+}
+
+func appendAudioDecoderConfiguration(e *Envelope, tag string, profile *Profile) {
+	e.Appendf(`<tt:%s token="%s"><tt:Name>ADC</tt:Name><tt:UseCount>1</tt:UseCount></tt:%s>`, tag, profile.Name, tag)
 }
 
 func GetStreamUriResponse(uri string) []byte {
@@ -247,10 +397,6 @@ func StaticResponse(operation string) []byte {
 	switch operation {
 	case DeviceGetSystemDateAndTime:
 		return GetSystemDateAndTimeResponse()
-	case MediaGetVideoEncoderConfiguration:
-		return GetVideoEncoderConfigurationResponse()
-	case MediaGetVideoEncoderConfigurations:
-		return GetVideoEncoderConfigurationsResponse()
 	}
 
 	e := NewEnvelope()
@@ -282,10 +428,6 @@ var responses = map[string]string{
 	<tds:Scopes><tt:ScopeDef>Fixed</tt:ScopeDef><tt:ScopeItem>onvif://www.onvif.org/type/Network_Video_Transmitter</tt:ScopeItem></tds:Scopes>
 </tds:GetScopesResponse>`,
 
-	MediaGetAudioEncoderConfigurations: `<trt:GetAudioEncoderConfigurationsResponse />`,
-	MediaGetAudioSources:               `<trt:GetAudioSourcesResponse />`,
-	MediaGetAudioSourceConfigurations:  `<trt:GetAudioSourceConfigurationsResponse />`,
-
 	MediaGetVideoEncoderConfigurationOptions: `<trt:GetVideoEncoderConfigurationOptionsResponse>
    <trt:Options>
        <tt:QualityRange><tt:Min>1</tt:Min><tt:Max>6</tt:Max></tt:QualityRange>
@@ -299,3 +441,48 @@ var responses = map[string]string{
    </trt:Options>
 </trt:GetVideoEncoderConfigurationOptionsResponse>`,
 }
+
+// NotAuthorizedResponse - SOAP fault for the request without valid WS-Security credentials
+func NotAuthorizedResponse() []byte {
+	e := NewEnvelope()
+	e.Append(`<s:Fault xmlns:ter="http://www.onvif.org/ver10/error">
+	<s:Code>
+		<s:Value>s:Sender</s:Value>
+		<s:Subcode><s:Value>ter:NotAuthorized</s:Value></s:Subcode>
+	</s:Code>
+	<s:Reason><s:Text xml:lang="en">Sender not Authorized</s:Text></s:Reason>
+</s:Fault>`)
+	return e.Bytes()
+}
+
+// NoProfileResponse - SOAP fault for the request with unknown profile token
+func NoProfileResponse() []byte {
+	e := NewEnvelope()
+	e.Append(`<s:Fault xmlns:ter="http://www.onvif.org/ver10/error">
+	<s:Code>
+		<s:Value>s:Sender</s:Value>
+		<s:Subcode>
+			<s:Value>ter:InvalidArgVal</s:Value>
+			<s:Subcode><s:Value>ter:NoProfile</s:Value></s:Subcode>
+		</s:Subcode>
+	</s:Code>
+	<s:Reason><s:Text xml:lang="en">Profile token does not exist</s:Text></s:Reason>
+</s:Fault>`)
+	return e.Bytes()
+}
+
+// NoConfigResponse - SOAP fault for the request with unknown configuration token
+func NoConfigResponse() []byte {
+	e := NewEnvelope()
+	e.Append(`<s:Fault xmlns:ter="http://www.onvif.org/ver10/error">
+	<s:Code>
+		<s:Value>s:Sender</s:Value>
+		<s:Subcode>
+			<s:Value>ter:InvalidArgVal</s:Value>
+			<s:Subcode><s:Value>ter:NoConfig</s:Value></s:Subcode>
+		</s:Subcode>
+	</s:Code>
+	<s:Reason><s:Text xml:lang="en">Configuration token does not exist</s:Text></s:Reason>
+</s:Fault>`)
+	return e.Bytes()
+}
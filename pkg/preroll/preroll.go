@@ -0,0 +1,336 @@
+package preroll
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/h264"
+	"github.com/AlexxIT/go2rtc/pkg/h265"
+	"github.com/pion/rtp"
+)
+
+// Buffer keeps the last Duration of the stream packets, starting from keyframe.
+// It works as a consumer for the stream and can replay saved packets to any
+// other consumer before live packets.
+type Buffer struct {
+	core.Connection
+	Duration time.Duration `json:"-"`
+
+	tracks  []*output
+	items   []*item
+	seq     uint64
+	video   bool
+	viewers []*viewer
+	mu      sync.Mutex
+}
+
+type output struct {
+	media *core.Media
+}
+
+type item struct {
+	seq      uint64
+	time     time.Time
+	trackID  int
+	packet   *core.Packet
+	keyframe bool
+}
+
+type viewer struct {
+	receivers []*core.Receiver // receiver for each track, nil if not used
+	live      bool
+}
+
+func NewBuffer(duration time.Duration) *Buffer {
+	medias := []*core.Media{
+		{
+			Kind:      core.KindVideo,
+			Direction: core.DirectionSendonly,
+			Codecs: []*core.Codec{
+				{Name: core.CodecH264},
+				{Name: core.CodecH265},
+			},
+		},
+		{
+			Kind:      core.KindAudio,
+			Direction: core.DirectionSendonly,
+			Codecs: []*core.Codec{
+				{Name: core.CodecAll},
+			},
+		},
+	}
+
+	return &Buffer{
+		Connection: core.Connection{
+			ID:         core.NewID(),
+			FormatName: "preroll",
+			Medias:     medias,
+		},
+		Duration: duration,
+	}
+}
+
+func (b *Buffer) AddTrack(media *core.Media, _ *core.Codec, track *core.Receiver) error {
+	trackID := len(b.tracks)
+
+	// video saved in AVC format, so the keyframe is always a single packet
+	codec := track.Codec.Clone()
+	sender := core.NewSender(media, track.Codec)
+
+	switch track.Codec.Name {
+	case core.CodecH264:
+		sender.Handler = func(packet *rtp.Packet) {
+			b.write(trackID, packet, h264.IsKeyframe(packet.Payload))
+		}
+
+		if track.Codec.IsRTP() {
+			sender.Handler = h264.RTPDepay(track.Codec, sender.Handler)
+		} else {
+			sender.Handler = h264.RepairAVCC(track.Codec, sender.Handler)
+		}
+
+		codec.PayloadType = core.PayloadTypeRAW
+
+	case core.CodecH265:
+		sender.Handler = func(packet *rtp.Packet) {
+			b.write(trackID, packet, h265.IsKeyframe(packet.Payload))
+		}
+
+		if track.Codec.IsRTP() {
+			sender.Handler = h265.RTPDepay(track.Codec, sender.Handler)
+		} else {
+			sender.Handler = h265.RepairAVCC(track.Codec, sender.Handler)
+		}
+
+		codec.PayloadType = core.PayloadTypeRAW
+
+	default:
+		if track.Codec.IsVideo() {
+			return errors.New("preroll: unsupported codec: " + track.Codec.String())
+		}
+
+		sender.Handler = func(packet *rtp.Packet) {
+			// audio can be start point only if there is no video
+			b.write(trackID, packet, !b.hasVideo())
+		}
+	}
+
+	b.mu.Lock()
+	if track.Codec.IsVideo() {
+		b.video = true
+	}
+	b.tracks = append(b.tracks, &output{
+		media: &core.Media{
+			Kind:      media.Kind,
+			Direction: core.DirectionRecvonly,
+			Codecs:    []*core.Codec{codec},
+		},
+	})
+	b.mu.Unlock()
+
+	sender.HandleRTP(track)
+	b.Senders = append(b.Senders, sender)
+
+	return nil
+}
+
+// AddConsumer adds tracks to consumer and sends it saved packets for the last
+// duration (starting from keyframe) and after that live packets.
+func (b *Buffer) AddConsumer(cons core.Consumer, duration time.Duration) error {
+	b.mu.Lock()
+	tracks := b.tracks
+	b.mu.Unlock()
+
+	v := &viewer{receivers: make([]*core.Receiver, len(tracks))}
+
+	var ok bool
+
+	for _, consMedia := range cons.GetMedias() {
+		for trackID, tr := range tracks {
+			if v.receivers[trackID] != nil {
+				continue
+			}
+
+			codec, consCodec := tr.media.MatchMedia(consMedia)
+			if codec == nil {
+				continue
+			}
+
+			receiver := core.NewReceiver(tr.media, codec)
+			if err := cons.AddTrack(consMedia, consCodec, receiver); err != nil {
+				continue
+			}
+
+			v.receivers[trackID] = receiver
+			ok = true
+
+			if !consMedia.MatchAll() {
+				break
+			}
+		}
+	}
+
+	if !ok {
+		return errors.New("preroll: codecs not matched")
+	}
+
+	b.mu.Lock()
+	seq := b.startSeq(time.Now().Add(-duration))
+	b.viewers = append(b.viewers, v)
+	b.mu.Unlock()
+
+	go b.replay(v, seq)
+
+	return nil
+}
+
+// Packets - number of saved packets
+func (b *Buffer) Packets() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.items)
+}
+
+func (b *Buffer) hasVideo() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.video
+}
+
+func (b *Buffer) write(trackID int, packet *rtp.Packet, keyframe bool) {
+	// payload can be reused by depayloader, so we need a copy
+	clone := *packet
+	clone.Payload = append([]byte(nil), packet.Payload...)
+
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// wait first keyframe
+	if len(b.items) == 0 && !keyframe {
+		return
+	}
+
+	b.seq++
+	b.items = append(b.items, &item{
+		seq: b.seq, time: now, trackID: trackID, packet: &clone, keyframe: keyframe,
+	})
+
+	if keyframe {
+		// remove everything before the last keyframe older than Duration
+		if i := b.keyframeIndex(now.Add(-b.Duration)); i > 0 {
+			clear(b.items[:i])
+			b.items = b.items[i:]
+		}
+	}
+
+	for i := 0; i < len(b.viewers); i++ {
+		v := b.viewers[i]
+		if !v.live {
+			continue
+		}
+		if !v.alive() {
+			v.close()
+			b.viewers = append(b.viewers[:i], b.viewers[i+1:]...)
+			i--
+			continue
+		}
+		if receiver := v.receivers[trackID]; receiver != nil {
+			receiver.Input(&clone)
+		}
+	}
+}
+
+// keyframeIndex return index of the last keyframe before time
+func (b *Buffer) keyframeIndex(before time.Time) int {
+	var n int
+	for i, item := range b.items {
+		if item.time.After(before) {
+			break
+		}
+		if item.keyframe {
+			n = i
+		}
+	}
+	return n
+}
+
+func (b *Buffer) startSeq(before time.Time) uint64 {
+	if len(b.items) == 0 {
+		return b.seq
+	}
+	return b.items[b.keyframeIndex(before)].seq - 1
+}
+
+// replayBatch less than the smallest sender buffer, so consumer won't drop packets
+const replayBatch = 32
+
+// replay sends saved packets to the viewer in small batches, and after
+// that switches viewer to live packets without gaps and duplicates
+func (b *Buffer) replay(v *viewer, seq uint64) {
+	for {
+		var batch []*item
+
+		b.mu.Lock()
+		for _, item := range b.items {
+			if item.seq <= seq {
+				continue
+			}
+			batch = append(batch, item)
+			if len(batch) == replayBatch {
+				break
+			}
+		}
+		if batch == nil {
+			v.live = true
+			b.mu.Unlock()
+			return
+		}
+		b.mu.Unlock()
+
+		for _, item := range batch {
+			if receiver := v.receivers[item.trackID]; receiver != nil {
+				receiver.Input(item.packet)
+			}
+			seq = item.seq
+		}
+
+		// give time to the consumer for processing the batch
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (v *viewer) alive() bool {
+	for _, receiver := range v.receivers {
+		if receiver != nil && len(receiver.Senders()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// close replay receivers, so consumer senders will be closed too
+func (v *viewer) close() {
+	for _, receiver := range v.receivers {
+		if receiver != nil {
+			receiver.Close()
+		}
+	}
+}
+
+func (b *Buffer) Stop() error {
+	b.mu.Lock()
+	viewers := b.viewers
+	b.items = nil
+	b.viewers = nil
+	b.mu.Unlock()
+
+	for _, v := range viewers {
+		v.close()
+	}
+
+	return b.Connection.Stop()
+}
@@ -0,0 +1,77 @@
+package preroll
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer(t *testing.T) {
+	b := NewBuffer(time.Second)
+
+	// wait first keyframe
+	b.write(0, &rtp.Packet{}, false)
+	require.Equal(t, 0, b.Packets())
+
+	b.write(0, &rtp.Packet{}, true)
+	b.write(0, &rtp.Packet{}, false)
+	require.Equal(t, 2, b.Packets())
+
+	b.write(0, &rtp.Packet{}, true)
+	b.write(0, &rtp.Packet{}, false)
+	require.Equal(t, 4, b.Packets())
+
+	// keep GOP with the last keyframe older than duration
+	for _, item := range b.items {
+		item.time = item.time.Add(-2 * time.Second)
+	}
+	b.write(0, &rtp.Packet{}, true)
+	require.Equal(t, 3, b.Packets())
+	require.Equal(t, b.items[0].seq-1, b.startSeq(time.Now().Add(-time.Second)))
+}
+
+func TestBufferStop(t *testing.T) {
+	b := NewBuffer(time.Second)
+
+	media := &core.Media{Kind: core.KindVideo, Direction: core.DirectionRecvonly}
+	codec := &core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: core.PayloadTypeRAW}
+
+	receiver := core.NewReceiver(media, codec)
+	sender := core.NewSender(media, codec)
+	sender.Handler = func(*rtp.Packet) {}
+	sender.HandleRTP(receiver)
+
+	b.viewers = append(b.viewers, &viewer{receivers: []*core.Receiver{receiver}})
+
+	// consumer should be disconnected from the replay receivers
+	require.Nil(t, b.Stop())
+	require.Empty(t, receiver.Senders())
+}
+
+func TestBufferAudioVideo(t *testing.T) {
+	b := NewBuffer(time.Second)
+
+	audioMedia := &core.Media{Kind: core.KindAudio, Direction: core.DirectionRecvonly}
+	audio := core.NewReceiver(audioMedia, &core.Codec{Name: core.CodecPCMA, ClockRate: 8000})
+	require.Nil(t, b.AddTrack(audioMedia, nil, audio))
+
+	// audio packets are processed while video track is added
+	done := make(chan struct{})
+	go func() {
+		for i := range 100 {
+			audio.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: uint16(i)}})
+		}
+		close(done)
+	}()
+
+	videoMedia := &core.Media{Kind: core.KindVideo, Direction: core.DirectionRecvonly}
+	video := core.NewReceiver(videoMedia, &core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: core.PayloadTypeRAW})
+	require.Nil(t, b.AddTrack(videoMedia, nil, video))
+	<-done
+
+	require.True(t, b.hasVideo())
+	require.Nil(t, b.Stop())
+}
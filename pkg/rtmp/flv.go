@@ -1,6 +1,8 @@
 package rtmp
 
 import (
+	"crypto/tls"
+
 	"github.com/AlexxIT/go2rtc/pkg/flv"
 )
 
@@ -19,6 +21,9 @@ func (c *Conn) Producer() (*flv.Producer, error) {
 
 	prod.FormatName = "rtmp"
 	prod.Protocol = "rtmp"
+	if _, ok := c.conn.(*tls.Conn); ok {
+		prod.Protocol = "rtmps"
+	}
 	prod.RemoteAddr = c.conn.RemoteAddr().String()
 	prod.URL = c.url
 
@@ -177,6 +177,10 @@ func (c *Conn) acceptCommand(b []byte) error {
 		c.Intent = cmd
 		c.streamID = 1
 
+		if len(items) >= 4 {
+			c.Stream, _ = items[3].(string)
+		}
+
 	default:
 		println("rtmp: unknown command: " + cmd)
 	}
@@ -196,6 +200,21 @@ func (c *Conn) WriteStart() error {
 	return c.writeMessage(3, TypeCommand, 0, payload)
 }
 
+// WriteReject sends error status for the publish or play command
+func (c *Conn) WriteReject(description string) error {
+	var code string
+	if c.Intent == CommandPublish {
+		code = "NetStream.Publish.BadName"
+	} else {
+		code = "NetStream.Play.Failed"
+	}
+
+	payload := amf.EncodeItems("onStatus", 0, nil, map[string]any{
+		"level": "error", "code": code, "description": description,
+	})
+	return c.writeMessage(3, TypeCommand, 0, payload)
+}
+
 func nowMS() uint32 {
 	return uint32(time.Now().UnixNano() / int64(time.Millisecond))
 }
@@ -30,6 +30,15 @@ func NewClient(uri string) *Conn {
 	}
 }
 
+// NewPublishClient - client for pushing stream to the remote RTSP server (ANNOUNCE > SETUP > RECORD).
+// Medias - consumer codecs filters.
+func NewPublishClient(uri string, medias []*core.Media) *Conn {
+	c := NewClient(uri)
+	c.Medias = medias
+	c.mode = core.ModeActiveConsumer
+	return c
+}
+
 func (c *Conn) Dial() (err error) {
 	if c.URL, err = url.Parse(c.uri); err != nil {
 		return
@@ -251,6 +260,70 @@ func (c *Conn) Record() (err error) {
 	return
 }
 
+// Publish - dial remote server and push all senders to it
+func (c *Conn) Publish() (err error) {
+	if len(c.Senders) == 0 {
+		return errors.New("rtsp: publish without tracks")
+	}
+
+	if err = c.Dial(); err != nil {
+		return
+	}
+
+	// convert senders to output medias, without direction like FFmpeg
+	c.Medias = make([]*core.Media, 0, len(c.Senders))
+	for i, sender := range c.Senders {
+		c.Medias = append(c.Medias, &core.Media{
+			Kind:   core.GetKind(sender.Codec.Name),
+			Codecs: []*core.Codec{sender.Codec},
+			ID:     "trackID=" + strconv.Itoa(i),
+		})
+	}
+
+	if err = c.Announce(); err != nil {
+		return
+	}
+
+	// SetupMedia should be called under the lock, like in GetTrack
+	c.stateMu.Lock()
+	err = c.setupSenders()
+	c.stateMu.Unlock()
+	if err != nil {
+		return
+	}
+
+	if err = c.Record(); err != nil {
+		return
+	}
+
+	c.stateMu.Lock()
+	c.playOK = true
+	c.state = StatePlay
+	c.stateMu.Unlock()
+
+	return nil
+}
+
+func (c *Conn) setupSenders() error {
+	for i, media := range c.Medias {
+		channel, err := c.SetupMedia(media)
+		if err != nil {
+			return err
+		}
+		// senders already use channels by their position
+		if channel != byte(i*2) {
+			return fmt.Errorf("rtsp: wrong channel %d for track %d", channel, i)
+		}
+		// server must tell where to send UDP packets
+		if c.Transport == "udp" && c.udpAddr[channel] == nil {
+			return fmt.Errorf("rtsp: no server_port for track %d", i)
+		}
+	}
+
+	c.state = StateSetup
+	return nil
+}
+
 func (c *Conn) SetupMedia(media *core.Media) (byte, error) {
 	var transport string
 
@@ -342,6 +415,7 @@ func (c *Conn) SetupMedia(media *core.Media) (byte, error) {
 
 		// Dahua:   RTP/AVP/UDP;unicast;client_port=49292-49293;server_port=43670-43671;ssrc=7CB694B4
 		// OpenIPC: RTP/AVP/UDP;unicast;client_port=59612-59613
+		var addr1, addr2 *net.UDPAddr
 		if s := core.Between(transport, "server_port=", ";"); s != "" {
 			s1, s2, _ := strings.Cut(s, "-")
 			port1 := core.Atoi(s1)
@@ -349,21 +423,24 @@ func (c *Conn) SetupMedia(media *core.Media) (byte, error) {
 			// TODO: more smart handling empty server ports
 			if port1 > 0 && port2 > 0 {
 				remoteIP := c.conn.RemoteAddr().(*net.TCPAddr).IP
-				c.udpAddr = append(c.udpAddr,
-					&net.UDPAddr{IP: remoteIP, Port: port1},
-					&net.UDPAddr{IP: remoteIP, Port: port2},
-				)
-
-				go func() {
-					// Try to open a hole in the NAT router (to allow incoming UDP packets)
-					// by send a UDP packet for RTP and RTCP to the remote RTSP server.
-					// https://github.com/FFmpeg/FFmpeg/blob/aa91ae25b88e195e6af4248e0ab30605735ca1cd/libavformat/rtpdec.c#L416-L438
-					_, _ = c.WriteToUDP([]byte{0x80, 0x00, 0x00, 0x00}, channel)
-					_, _ = c.WriteToUDP([]byte{0x80, 0xC8, 0x00, 0x01}, channel+1)
-				}()
+				addr1 = &net.UDPAddr{IP: remoteIP, Port: port1}
+				addr2 = &net.UDPAddr{IP: remoteIP, Port: port2}
 			}
 		}
 
+		// keep the same indexes for udpConn and udpAddr, even without server ports
+		c.udpAddr = append(c.udpAddr, addr1, addr2)
+
+		if addr1 != nil {
+			go func() {
+				// Try to open a hole in the NAT router (to allow incoming UDP packets)
+				// by send a UDP packet for RTP and RTCP to the remote RTSP server.
+				// https://github.com/FFmpeg/FFmpeg/blob/aa91ae25b88e195e6af4248e0ab30605735ca1cd/libavformat/rtpdec.c#L416-L438
+				_, _ = c.WriteToUDP([]byte{0x80, 0x00, 0x00, 0x00}, channel)
+				_, _ = c.WriteToUDP([]byte{0x80, 0xC8, 0x00, 0x01}, channel+1)
+			}()
+		}
+
 		return channel, nil
 	} else {
 		// we send our `interleaved`, but camera can answer with another
@@ -395,20 +472,33 @@ func (c *Conn) Teardown() (err error) {
 }
 
 func (c *Conn) Close() error {
-	if c.mode == core.ModeActiveProducer {
+	if c.mode == core.ModeActiveProducer || c.mode == core.ModeActiveConsumer {
 		_ = c.Teardown()
 	}
 	if c.OnClose != nil {
 		_ = c.OnClose()
 	}
 	for _, conn := range c.udpConn {
-		_ = conn.Close()
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+	if c.conn == nil {
+		return nil // multicast consumer
 	}
 	return c.conn.Close()
 }
 
 func (c *Conn) WriteToUDP(b []byte, channel byte) (int, error) {
-	return c.udpConn[channel].WriteToUDP(b, c.udpAddr[channel])
+	c.stateMu.Lock()
+	if int(channel) >= len(c.udpConn) || int(channel) >= len(c.udpAddr) || c.udpConn[channel] == nil || c.udpAddr[channel] == nil {
+		c.stateMu.Unlock()
+		return 0, nil // skip channels without setup
+	}
+	conn, addr := c.udpConn[channel], c.udpAddr[channel]
+	c.stateMu.Unlock()
+
+	return conn.WriteToUDP(b, addr)
 }
 
 const listenUDPAttemps = 10
@@ -1,11 +1,18 @@
 package rtsp
 
 import (
+	"bufio"
+	"fmt"
 	"net"
+	"net/textproto"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/tcp"
+	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -93,3 +100,180 @@ Session: 1
 	require.Nil(t, err)
 	require.Equal(t, ch, byte(4))
 }
+
+func TestClientPublish(t *testing.T) {
+	Timeout = 5 * time.Second
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.Nil(t, err)
+
+	packets := make(chan *rtp.Packet, 10)
+	errs := make(chan error, 1)
+
+	// wait for the server, so it doesn't run in the next tests
+	closed := make(chan struct{})
+	defer func() { <-closed }()
+	defer ln.Close()
+
+	go func() {
+		defer close(closed)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		server := NewServer(conn)
+		server.Listen(func(msg any) {
+			if msg == MethodAnnounce {
+				receiver := server.Receivers[0]
+				sender := core.NewSender(receiver.Media, receiver.Codec)
+				sender.Handler = func(packet *rtp.Packet) {
+					packets <- packet
+				}
+				sender.HandleRTP(receiver)
+			}
+		})
+
+		if err = server.Accept(); err == nil {
+			_ = server.Handle()
+		}
+	}()
+
+	media := &core.Media{
+		Kind: core.KindVideo, Direction: core.DirectionSendonly,
+		Codecs: []*core.Codec{{Name: core.CodecAny}},
+	}
+	client := NewPublishClient("rtsp://"+ln.Addr().String()+"/stream", []*core.Media{media})
+
+	codec := &core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: 96}
+	track := core.NewReceiver(&core.Media{Kind: core.KindVideo, Direction: core.DirectionRecvonly}, codec)
+	require.Nil(t, client.AddTrack(media, codec, track))
+
+	require.Nil(t, client.Publish())
+	require.Len(t, client.Medias, 1)
+
+	go func() {
+		_ = client.Handle()
+	}()
+	defer client.Stop()
+
+	for i := uint16(0); ; i++ {
+		track.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: i, Marker: true}, Payload: []byte{0x65, 0x00}})
+
+		select {
+		case packet := <-packets:
+			require.Equal(t, []byte{0x65, 0x00}, packet.Payload)
+			return
+		case err = <-errs:
+			require.Nil(t, err)
+		case <-time.After(10 * time.Millisecond):
+			require.Less(t, i, uint16(300), "no RTP packets")
+		}
+	}
+}
+
+func TestClientPublishUDP(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.Nil(t, err)
+
+	udp, err := net.ListenUDP("udp", &net.UDPAddr{IP: ln.Addr().(*net.TCPAddr).IP})
+	require.Nil(t, err)
+	defer udp.Close()
+
+	serverPort := udp.LocalAddr().(*net.UDPAddr).Port
+
+	// wait for the server, so it doesn't run in the next tests
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer ln.Close()
+
+	// simple RECORD server, go2rtc server doesn't support UDP for publishing
+	serve := func(withPort bool) {
+		defer wg.Done()
+
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		rd := bufio.NewReader(conn)
+		for {
+			req, err := tcp.ReadRequest(rd)
+			if err != nil {
+				return
+			}
+
+			res := &tcp.Response{
+				Proto:  ProtoRTSP,
+				Status: "200 OK",
+				Header: textproto.MIMEHeader{"Cseq": {req.Header.Get("CSeq")}},
+			}
+			if req.Method == MethodSetup {
+				tr := req.Header.Get("Transport")
+				if withPort {
+					tr += fmt.Sprintf(";server_port=%d-%d", serverPort, serverPort+1)
+				}
+				res.Header.Set("Transport", tr)
+				res.Header.Set("Session", "1")
+			}
+			if err = res.Write(conn); err != nil {
+				return
+			}
+		}
+	}
+
+	publish := func() (*Conn, *core.Receiver, error) {
+		media := &core.Media{
+			Kind: core.KindVideo, Direction: core.DirectionSendonly,
+			Codecs: []*core.Codec{{Name: core.CodecAny}},
+		}
+		client := NewPublishClient("rtsp://"+ln.Addr().String()+"/stream", []*core.Media{media})
+		client.Transport = "udp"
+
+		codec := &core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: 96}
+		track := core.NewReceiver(&core.Media{Kind: core.KindVideo, Direction: core.DirectionRecvonly}, codec)
+		if err := client.AddTrack(media, codec, track); err != nil {
+			return nil, nil, err
+		}
+
+		return client, track, client.Publish()
+	}
+
+	// server without server_port in the SETUP response
+	wg.Add(1)
+	go serve(false)
+
+	client, _, err := publish()
+	require.EqualError(t, err, "rtsp: no server_port for track 0")
+	_ = client.Stop()
+
+	wg.Add(1)
+	go serve(true)
+
+	client, track, err := publish()
+	require.Nil(t, err)
+
+	go func() {
+		_ = client.Handle()
+	}()
+	defer client.Stop()
+
+	track.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 1, Marker: true}, Payload: []byte{0x65, 0x00}})
+
+	b := make([]byte, 1500)
+	for {
+		require.Nil(t, udp.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := udp.ReadFromUDP(b)
+		require.Nil(t, err)
+
+		// skip NAT hole packets
+		packet := &rtp.Packet{}
+		if err = packet.Unmarshal(b[:n]); err == nil && len(packet.Payload) > 0 {
+			require.Equal(t, []byte{0x65, 0x00}, packet.Payload)
+			return
+		}
+	}
+}
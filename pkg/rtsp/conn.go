@@ -14,6 +14,7 @@ import (
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
 	"github.com/AlexxIT/go2rtc/pkg/tcp"
+	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 )
 
@@ -26,6 +27,7 @@ type Conn struct {
 	Backchannel bool
 	Media       string
 	OnClose     func() error
+	OnMulticast func() (group *net.UDPAddr, ttl int, err error) // multicast support for server
 	PacketSize  uint16
 	SessionName string
 	Timeout     int
@@ -37,6 +39,7 @@ type Conn struct {
 
 	auth      *tcp.Auth
 	conn      net.Conn
+	forbidden bool
 	keepalive int
 	mode      core.Mode
 	playOK    bool
@@ -51,6 +54,10 @@ type Conn struct {
 
 	udpConn []*net.UDPConn
 	udpAddr []*net.UDPAddr
+
+	transports []string // server consumer transport per track: tcp, udp or multicast
+
+	stats rtcpStats
 }
 
 const (
@@ -130,6 +137,24 @@ func (c *Conn) Handle() (err error) {
 		// pushing frames to remote RTSP Client (ex VLC)
 		timeout = time.Second * 60
 
+	case core.ModeActiveConsumer:
+		// pushing frames to remote RTSP Server (ex MediaMTX),
+		// server may send nothing except keepalive responses
+		keepaliveDT := 25 * time.Second
+		if c.keepalive > 5 {
+			keepaliveDT = time.Duration(c.keepalive-5) * time.Second
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go c.handleKeepalive(ctx, keepaliveDT)
+		defer cancel()
+
+		if c.Timeout == 0 {
+			timeout = keepaliveDT + time.Second*5
+		} else {
+			timeout = time.Second * time.Duration(c.Timeout)
+		}
+
 	default:
 		return fmt.Errorf("wrong RTSP conn mode: %d", c.mode)
 	}
@@ -138,7 +163,15 @@ func (c *Conn) Handle() (err error) {
 		go c.handleUDPData(byte(i))
 	}
 
-	for c.state != StateNone {
+	ctx, cancel := context.WithCancel(context.Background())
+	go c.handleRTCP(ctx)
+	defer cancel()
+
+	for {
+		if state, _ := c.playState(); state == StateNone {
+			break
+		}
+
 		ts := time.Now()
 
 		_ = c.conn.SetReadDeadline(ts.Add(timeout))
@@ -151,6 +184,22 @@ func (c *Conn) Handle() (err error) {
 	return
 }
 
+func (c *Conn) playState() (State, bool) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.state, c.playOK
+}
+
+// trackTransport - returns server consumer transport for RTP or RTCP channel
+func (c *Conn) trackTransport(channel byte) string {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if i := int(channel / 2); i < len(c.transports) {
+		return c.transports[i]
+	}
+	return ""
+}
+
 func (c *Conn) handleKeepalive(ctx context.Context, d time.Duration) {
 	ticker := time.NewTicker(d)
 	for {
@@ -169,6 +218,9 @@ func (c *Conn) handleKeepalive(ctx context.Context, d time.Duration) {
 func (c *Conn) handleUDPData(channel byte) {
 	// TODO: handle timeouts and drop TCP connection after any error
 	conn := c.udpConn[channel]
+	if conn == nil {
+		return
+	}
 
 	for {
 		// TP-Link Tapo camera has crazy 10000 bytes packet size
@@ -210,7 +262,9 @@ func (c *Conn) handleTCPData() error {
 			}
 			c.Fire(res)
 			// for playing backchannel only after OK response on play
+			c.stateMu.Lock()
 			c.playOK = true
+			c.stateMu.Unlock()
 			return nil
 
 		case "OPTI", "TEAR", "DESC", "SETU", "PLAY", "PAUS", "RECO", "ANNO", "GET_", "SET_":
@@ -295,6 +349,7 @@ func (c *Conn) handleRawPacket(channel byte, buf []byte) error {
 
 		for _, receiver := range c.Receivers {
 			if receiver.ID == channel {
+				c.stats.received(channel, receiver.Codec.ClockRate, packet, time.Now())
 				receiver.WriteRTP(packet)
 				break
 			}
@@ -306,11 +361,10 @@ func (c *Conn) handleRawPacket(channel byte, buf []byte) error {
 			return nil
 		}
 
-		//var err error
-		//msg.Packets, err = rtcp.Unmarshal(buf)
-		//if err != nil {
-		//	return nil
-		//}
+		var err error
+		if msg.Packets, err = rtcp.Unmarshal(buf); err == nil {
+			c.stats.report(channel, msg.Packets, time.Now())
+		}
 
 		c.Fire(msg)
 	}
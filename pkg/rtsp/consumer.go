@@ -37,7 +37,7 @@ func (c *Conn) AddTrack(media *core.Media, codec *core.Codec, track *core.Receiv
 
 		c.state = StateSetup
 
-	case core.ModePassiveConsumer:
+	case core.ModePassiveConsumer, core.ModeActiveConsumer:
 		channel = byte(len(c.Senders)) * 2
 
 		// for consumer is better to use original track codec
@@ -93,7 +93,8 @@ func (c *Conn) packetWriter(codec *core.Codec, channel, payloadType uint8) core.
 	}
 
 	handlerFunc := func(packet *rtp.Packet) {
-		if c.state == StateNone {
+		state, playOK := c.playState()
+		if state == StateNone {
 			return
 		}
 
@@ -113,6 +114,8 @@ func (c *Conn) packetWriter(codec *core.Codec, channel, payloadType uint8) core.
 			packet.Marker = true // better to have marker on all audio packets
 		}
 
+		c.stats.sent(channel, codec.ClockRate, &clone, time.Now())
+
 		size := rtpHdr + len(packet.Payload)
 
 		if l := len(buf); n+intHdr+size > l {
@@ -138,7 +141,7 @@ func (c *Conn) packetWriter(codec *core.Codec, channel, payloadType uint8) core.
 
 		n += 4 + size
 
-		if !packet.Marker || !c.playOK {
+		if !packet.Marker || !playOK {
 			// collect continious video packets to buffer
 			// or wait OK for PLAY command for backchannel
 			//log.Printf("[rtsp] collecting buffer ok=%t", c.playOK)
@@ -176,7 +179,8 @@ func (c *Conn) packetWriter(codec *core.Codec, channel, payloadType uint8) core.
 }
 
 func (c *Conn) writeInterleavedData(data []byte) error {
-	if c.Transport != "udp" {
+	// client uses one transport for all tracks, server - transport per track
+	if c.Transport != "udp" && (len(data) < 4 || c.trackTransport(data[1]) != "udp") {
 		_ = c.conn.SetWriteDeadline(time.Now().Add(Timeout))
 		_, err := c.conn.Write(data)
 		return err
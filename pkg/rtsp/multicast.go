@@ -0,0 +1,47 @@
+package rtsp
+
+import (
+	"net"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"golang.org/x/net/ipv4"
+)
+
+// NewMulticast - consumer that sends RTP packets of all tracks to the multicast group.
+// Track N uses group port+N*2 for RTP and port+N*2+1 for RTCP.
+func NewMulticast(medias []*core.Media, group *net.UDPAddr, ttl int) (*Conn, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl > 0 {
+		if err = ipv4.NewPacketConn(conn).SetMulticastTTL(ttl); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	c := &Conn{
+		Connection: core.Connection{
+			ID:         core.NewID(),
+			FormatName: "rtsp",
+			Protocol:   "udp+multicast",
+			RemoteAddr: group.String(),
+			Medias:     medias,
+		},
+		mode:      core.ModePassiveConsumer,
+		playOK:    true,
+		state:     StatePlay,
+		Transport: "udp",
+	}
+
+	for i := range medias {
+		for j := 0; j < 2; j++ {
+			c.udpConn = append(c.udpConn, conn)
+			c.udpAddr = append(c.udpAddr, &net.UDPAddr{IP: group.IP, Port: group.Port + i*2 + j})
+		}
+	}
+
+	return c, nil
+}
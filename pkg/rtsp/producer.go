@@ -1,7 +1,6 @@
 package rtsp
 
 import (
-	"encoding/json"
 	"errors"
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
@@ -109,10 +108,6 @@ func (c *Conn) Stop() (err error) {
 	return
 }
 
-func (c *Conn) MarshalJSON() ([]byte, error) {
-	return json.Marshal(c.Connection)
-}
-
 func (c *Conn) Reconnect() error {
 	c.Fire("RTSP reconnect")
 
@@ -0,0 +1,312 @@
+package rtsp
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+)
+
+// RTCPInterval - interval for Sender and Receiver Reports
+var RTCPInterval = 5 * time.Second
+
+// Stats - RTP/RTCP statistics for one track (RTP channel)
+type Stats struct {
+	Channel byte   `json:"channel"`
+	SSRC    uint32 `json:"ssrc,omitempty"`
+
+	// receiving side, calculated from RTP packets (RFC 3550 A.3, A.8)
+	PacketsReceived uint32     `json:"packets_received,omitempty"`
+	PacketsLost     int32      `json:"packets_lost,omitempty"`
+	Jitter          float64    `json:"jitter,omitempty"`      // milliseconds
+	SenderTime      *time.Time `json:"sender_time,omitempty"` // NTP time from the last Sender Report
+
+	// sending side, remote values from the last Receiver Report
+	PacketsSent        uint32  `json:"packets_sent,omitempty"`
+	RemotePacketsLost  int32   `json:"remote_packets_lost,omitempty"`
+	RemoteFractionLost float64 `json:"remote_fraction_lost,omitempty"` // from 0 to 1
+	RemoteJitter       float64 `json:"remote_jitter,omitempty"`        // milliseconds
+
+	clockRate uint32
+
+	// receiving
+	baseSeq       uint16
+	maxSeq        uint16
+	cycles        uint32
+	expectedPrior uint32
+	receivedPrior uint32
+	firstArrival  time.Time
+	transit       uint32
+	jitter        float64 // in timestamp units
+	lastSR        uint32  // middle 32 bits of NTP time from the last Sender Report
+	lastSRTime    time.Time
+
+	// sending
+	octetsSent uint32
+	lastTS     uint32
+	lastTSTime time.Time
+}
+
+type rtcpStats struct {
+	ssrc     uint32 // our SSRC for Receiver Reports
+	channels map[byte]*Stats
+	mu       sync.Mutex
+}
+
+func (s *rtcpStats) get(channel byte, clockRate uint32) *Stats {
+	if s.channels == nil {
+		s.channels = map[byte]*Stats{}
+		s.ssrc = rand.Uint32()
+	}
+	st := s.channels[channel]
+	if st == nil {
+		st = &Stats{Channel: channel, clockRate: clockRate}
+		s.channels[channel] = st
+	}
+	return st
+}
+
+// received updates statistics for incoming RTP packet
+func (s *rtcpStats) received(channel byte, clockRate uint32, packet *rtp.Packet, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.get(channel, clockRate)
+
+	seq := packet.SequenceNumber
+
+	if st.PacketsReceived == 0 {
+		st.SSRC = packet.SSRC
+		st.baseSeq = seq
+		st.maxSeq = seq
+	} else if delta := seq - st.maxSeq; delta < 3000 {
+		// in order, with permissible gap
+		if seq < st.maxSeq {
+			st.cycles += 1 << 16
+		}
+		st.maxSeq = seq
+	}
+
+	st.PacketsReceived++
+
+	expected := st.cycles + uint32(st.maxSeq) - uint32(st.baseSeq) + 1
+	st.PacketsLost = int32(expected - st.PacketsReceived)
+
+	if st.clockRate > 0 {
+		if st.PacketsReceived == 1 {
+			st.firstArrival = now
+		}
+
+		// arrival time in timestamp units, relative to the first packet,
+		// split to seconds and remainder to avoid int64 overflow
+		elapsed := now.Sub(st.firstArrival)
+		arrival := int64(elapsed/time.Second)*int64(st.clockRate) +
+			int64(elapsed%time.Second)*int64(st.clockRate)/int64(time.Second)
+
+		// wraps around like RTP timestamp
+		transit := uint32(arrival) - packet.Timestamp
+		if st.PacketsReceived > 1 {
+			d := float64(int32(transit - st.transit))
+			if d < 0 {
+				d = -d
+			}
+			st.jitter += (d - st.jitter) / 16
+			st.Jitter = st.jitter * 1000 / float64(st.clockRate)
+		}
+		st.transit = transit
+	}
+}
+
+// sent updates statistics for outgoing RTP packet
+func (s *rtcpStats) sent(channel byte, clockRate uint32, packet *rtp.Packet, now time.Time) {
+	s.mu.Lock()
+	st := s.get(channel, clockRate)
+	st.SSRC = packet.SSRC
+	st.PacketsSent++
+	st.octetsSent += uint32(len(packet.Payload))
+	st.lastTS = packet.Timestamp
+	st.lastTSTime = now
+	s.mu.Unlock()
+}
+
+// report updates statistics from incoming RTCP packets on the control channel
+func (s *rtcpStats) report(channel byte, packets []rtcp.Packet, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.channels == nil {
+		return
+	}
+
+	st := s.channels[channel-1]
+	if st == nil {
+		return
+	}
+
+	for _, packet := range packets {
+		switch packet := packet.(type) {
+		case *rtcp.SenderReport:
+			ts := fromNTP(packet.NTPTime)
+			st.SenderTime = &ts
+			st.lastSR = uint32(packet.NTPTime >> 16)
+			st.lastSRTime = now
+			st.remoteReports(packet.Reports)
+		case *rtcp.ReceiverReport:
+			st.remoteReports(packet.Reports)
+		}
+	}
+}
+
+func (st *Stats) remoteReports(reports []rtcp.ReceptionReport) {
+	for _, report := range reports {
+		if st.PacketsSent == 0 || report.SSRC != st.SSRC {
+			continue
+		}
+		st.RemotePacketsLost = int32(report.TotalLost<<8) >> 8 // signed 24 bit
+		st.RemoteFractionLost = float64(report.FractionLost) / 256
+		if st.clockRate > 0 {
+			st.RemoteJitter = float64(report.Jitter) * 1000 / float64(st.clockRate)
+		}
+	}
+}
+
+// packets return Sender Reports for sending tracks and Receiver Reports for receiving tracks
+func (s *rtcpStats) packets(now time.Time) map[byte]rtcp.Packet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	packets := map[byte]rtcp.Packet{}
+
+	for channel, st := range s.channels {
+		if st.PacketsSent > 0 {
+			rtpTime := st.lastTS
+			if st.clockRate > 0 {
+				rtpTime += uint32(now.Sub(st.lastTSTime) * time.Duration(st.clockRate) / time.Second)
+			}
+			packets[channel+1] = &rtcp.SenderReport{
+				SSRC:        st.SSRC,
+				NTPTime:     toNTP(now),
+				RTPTime:     rtpTime,
+				PacketCount: st.PacketsSent,
+				OctetCount:  st.octetsSent,
+			}
+		} else if st.PacketsReceived > 0 {
+			expected := st.cycles + uint32(st.maxSeq) - uint32(st.baseSeq) + 1
+			expectedInterval := expected - st.expectedPrior
+			receivedInterval := st.PacketsReceived - st.receivedPrior
+			st.expectedPrior = expected
+			st.receivedPrior = st.PacketsReceived
+
+			var fraction uint8
+			if lostInterval := int64(expectedInterval) - int64(receivedInterval); expectedInterval > 0 && lostInterval > 0 {
+				fraction = uint8(lostInterval << 8 / int64(expectedInterval))
+			}
+
+			report := rtcp.ReceptionReport{
+				SSRC:               st.SSRC,
+				FractionLost:       fraction,
+				TotalLost:          uint32(st.PacketsLost) & 0xFFFFFF,
+				LastSequenceNumber: st.cycles | uint32(st.maxSeq),
+				Jitter:             uint32(st.jitter),
+				LastSenderReport:   st.lastSR,
+			}
+			if st.lastSR != 0 {
+				report.Delay = uint32(now.Sub(st.lastSRTime) * 65536 / time.Second)
+			}
+
+			packets[channel+1] = &rtcp.ReceiverReport{SSRC: s.ssrc, Reports: []rtcp.ReceptionReport{report}}
+		}
+	}
+
+	return packets
+}
+
+func (s *rtcpStats) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]*Stats, 0, len(s.channels))
+	for _, st := range s.channels {
+		items = append(items, st)
+	}
+	slices.SortFunc(items, func(a, b *Stats) int {
+		return int(a.Channel) - int(b.Channel)
+	})
+
+	return json.Marshal(items)
+}
+
+func (c *Conn) handleRTCP(ctx context.Context) {
+	ticker := time.NewTicker(RTCPInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			if state, playOK := c.playState(); state == StateNone || !playOK {
+				continue
+			}
+
+			for channel, packet := range c.stats.packets(now) {
+				// multicast clients get packets from the shared consumer
+				if c.trackTransport(channel) == "multicast" {
+					continue
+				}
+				if err := c.writeRTCP(channel, packet); err != nil {
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Conn) writeRTCP(channel byte, packet rtcp.Packet) error {
+	b, err := packet.Marshal()
+	if err != nil {
+		return err
+	}
+
+	size := len(b)
+	data := append([]byte{'$', channel, byte(size >> 8), byte(size)}, b...)
+
+	return c.writeInterleavedData(data)
+}
+
+func (c *Conn) MarshalJSON() ([]byte, error) {
+	c.stats.mu.Lock()
+	empty := len(c.stats.channels) == 0
+	c.stats.mu.Unlock()
+
+	if empty {
+		return json.Marshal(c.Connection)
+	}
+
+	return json.Marshal(struct {
+		core.Connection
+		RTCP *rtcpStats `json:"rtcp"`
+	}{c.Connection, &c.stats})
+}
+
+// seconds between 1900 (NTP epoch) and 1970 (Unix epoch)
+const ntpEpochOffset = 2208988800
+
+func toNTP(t time.Time) uint64 {
+	ns := uint64(t.UnixNano())
+	sec := ns/1e9 + ntpEpochOffset
+	frac := (ns % 1e9) << 32 / 1e9
+	return sec<<32 | frac
+}
+
+func fromNTP(ntp uint64) time.Time {
+	sec := int64(ntp>>32) - ntpEpochOffset
+	ns := int64((ntp & 0xFFFFFFFF) * 1e9 >> 32)
+	return time.Unix(sec, ns)
+}
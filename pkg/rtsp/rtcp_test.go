@@ -0,0 +1,67 @@
+package rtsp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTCPStats(t *testing.T) {
+	var stats rtcpStats
+
+	now := time.Now()
+
+	// packet 65534 lost, sequence wraps around
+	for i, seq := range []uint16{65532, 65533, 65535, 0, 1} {
+		packet := &rtp.Packet{Header: rtp.Header{SSRC: 123, SequenceNumber: seq, Timestamp: uint32(i * 9000)}}
+		stats.received(0, 90000, packet, now.Add(time.Duration(i)*100*time.Millisecond))
+	}
+
+	st := stats.channels[0]
+	require.Equal(t, uint32(5), st.PacketsReceived)
+	require.Equal(t, int32(1), st.PacketsLost)
+	require.Equal(t, 0.0, st.Jitter)
+
+	// no jitter for long sessions and RTP timestamp wraps around
+	var long rtcpStats
+	for i := range 10 {
+		ts := uint32(uint64(i) * 30 * 3600 * 90000)
+		long.received(0, 90000, &rtp.Packet{Header: rtp.Header{Timestamp: ts}}, now.Add(time.Duration(i)*30*time.Hour))
+	}
+	require.Equal(t, 0.0, long.channels[0].Jitter)
+
+	ntp := toNTP(now)
+	require.WithinDuration(t, now, fromNTP(ntp), time.Microsecond)
+
+	stats.report(1, []rtcp.Packet{&rtcp.SenderReport{SSRC: 123, NTPTime: ntp}}, now)
+	require.NotNil(t, st.SenderTime)
+
+	packets := stats.packets(now.Add(time.Second))
+	rr := packets[1].(*rtcp.ReceiverReport)
+	require.Len(t, rr.Reports, 1)
+	require.Equal(t, uint32(123), rr.Reports[0].SSRC)
+	require.Equal(t, uint8(256/6), rr.Reports[0].FractionLost)
+	require.Equal(t, uint32(1), rr.Reports[0].TotalLost)
+	require.Equal(t, uint32(1<<16|1), rr.Reports[0].LastSequenceNumber)
+	require.Equal(t, uint32(65536), rr.Reports[0].Delay)
+
+	// sending side
+	stats.sent(2, 8000, &rtp.Packet{Header: rtp.Header{SSRC: 456, Timestamp: 1000}, Payload: make([]byte, 160)}, now)
+	stats.report(3, []rtcp.Packet{&rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{
+		{SSRC: 456, FractionLost: 64, TotalLost: 10, Jitter: 80},
+	}}}, now)
+
+	packets = stats.packets(now.Add(time.Second))
+	sr := packets[3].(*rtcp.SenderReport)
+	require.Equal(t, uint32(9000), sr.RTPTime)
+	require.Equal(t, uint32(1), sr.PacketCount)
+	require.Equal(t, uint32(160), sr.OctetCount)
+
+	b, err := json.Marshal(&stats)
+	require.Nil(t, err)
+	require.Contains(t, string(b), `"remote_packets_lost":10,"remote_fraction_lost":0.25,"remote_jitter":10`)
+}
@@ -2,10 +2,12 @@ package rtsp
 
 import (
 	"bufio"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -14,9 +16,10 @@ import (
 )
 
 var FailedAuth = errors.New("failed authentication")
+var Forbidden = errors.New("forbidden")
 
 func NewServer(conn net.Conn) *Conn {
-	return &Conn{
+	c := &Conn{
 		Connection: core.Connection{
 			ID:         core.NewID(),
 			FormatName: "rtsp",
@@ -26,6 +29,10 @@ func NewServer(conn net.Conn) *Conn {
 		conn:   conn,
 		reader: bufio.NewReader(conn),
 	}
+	if _, ok := conn.(*tls.Conn); ok {
+		c.Protocol = "rtsps+tcp"
+	}
+	return c
 }
 
 func (c *Conn) Auth(username, password string) {
@@ -33,6 +40,21 @@ func (c *Conn) Auth(username, password string) {
 	c.auth = tcp.NewAuth(info)
 }
 
+// AuthFunc - validate client credentials with custom function
+func (c *Conn) AuthFunc(validate func(username, password string) bool) {
+	c.auth = tcp.NewAuthFunc(validate)
+}
+
+// Username return the name of the authenticated user
+func (c *Conn) Username() string {
+	return c.auth.Username()
+}
+
+// Forbid - reject current DESCRIBE or ANNOUNCE request from the handler
+func (c *Conn) Forbid() {
+	c.forbidden = true
+}
+
 func (c *Conn) Accept() error {
 	for {
 		req, err := c.ReadRequest()
@@ -100,6 +122,10 @@ func (c *Conn) Accept() error {
 			c.mode = core.ModePassiveProducer
 			c.Fire(MethodAnnounce)
 
+			if c.forbidden {
+				return c.writeForbidden(req)
+			}
+
 			res := &tcp.Response{Request: req}
 			if err = c.WriteResponse(res); err != nil {
 				return err
@@ -109,6 +135,10 @@ func (c *Conn) Accept() error {
 			c.mode = core.ModePassiveConsumer
 			c.Fire(MethodDescribe)
 
+			if c.forbidden {
+				return c.writeForbidden(req)
+			}
+
 			if c.Senders == nil {
 				res := &tcp.Response{
 					Status:  "404 Not Found",
@@ -167,12 +197,12 @@ func (c *Conn) Accept() error {
 			// This allows smart clients who initially requested UDP to fall back on TCP transport
 			if tr := req.Header.Get("Transport"); strings.HasPrefix(tr, "RTP/AVP/TCP") {
 				c.session = core.RandString(8, 10)
-				c.state = StateSetup
+				c.setState(StateSetup)
 
 				if c.mode == core.ModePassiveConsumer {
 					if i := reqTrackID(req); i >= 0 && i < len(c.Senders)+len(c.Receivers) {
 						if i < len(c.Senders) {
-							c.Senders[i].Media.ID = MethodSetup
+							c.setTransport(i, "tcp")
 						} else {
 							c.Receivers[i-len(c.Senders)].Media.ID = MethodSetup
 						}
@@ -184,6 +214,14 @@ func (c *Conn) Accept() error {
 				} else {
 					res.Header.Set("Transport", tr)
 				}
+			} else if c.mode == core.ModePassiveConsumer && strings.HasPrefix(tr, "RTP/AVP") {
+				if tr, err = c.setupUDP(req, tr); err == nil {
+					c.session = core.RandString(8, 10)
+					c.setState(StateSetup)
+					res.Header.Set("Transport", tr)
+				} else {
+					res.Status = "461 Unsupported transport"
+				}
 			} else {
 				res.Status = "461 Unsupported transport"
 			}
@@ -194,23 +232,29 @@ func (c *Conn) Accept() error {
 
 		case MethodRecord, MethodPlay:
 			if c.mode == core.ModePassiveConsumer {
-				// stop unconfigured senders
-				for _, track := range c.Senders {
-					if track.Media.ID != MethodSetup {
+				// stop unconfigured senders and senders of multicast tracks,
+				// multicast tracks will be sent by shared multicast consumer
+				for i, track := range c.Senders {
+					if tr := c.trackTransport(byte(i * 2)); tr == "" || tr == "multicast" {
 						track.Close()
 					}
 				}
+				c.Protocol = c.transportProtocol()
 			}
 
 			res := &tcp.Response{Request: req}
 			err = c.WriteResponse(res)
+
+			c.stateMu.Lock()
 			c.playOK = true
+			c.stateMu.Unlock()
+
 			return err
 
 		case MethodTeardown:
 			res := &tcp.Response{Request: req}
 			_ = c.WriteResponse(res)
-			c.state = StateNone
+			c.setState(StateNone)
 			return c.conn.Close()
 
 		default:
@@ -219,6 +263,110 @@ func (c *Conn) Accept() error {
 	}
 }
 
+// setupUDP - setup unicast or multicast UDP transport for the consumer track
+func (c *Conn) setupUDP(req *tcp.Request, tr string) (string, error) {
+	// plain RTP over UDP would bypass the encryption
+	if _, ok := c.conn.(*tls.Conn); ok {
+		return "", errors.New("rtsp: only TCP transport for RTSPS")
+	}
+
+	i := reqTrackID(req)
+	if i < 0 || i >= len(c.Senders) {
+		return "", errors.New("rtsp: UDP transport only for server tracks")
+	}
+
+	channel := i * 2
+
+	if strings.Contains(tr, "multicast") {
+		if c.OnMulticast == nil {
+			return "", errors.New("rtsp: multicast not supported")
+		}
+
+		group, ttl, err := c.OnMulticast()
+		if err != nil {
+			return "", err
+		}
+
+		// track will be sent by shared multicast consumer,
+		// so the sender will be stopped on PLAY
+		c.setTransport(i, "multicast")
+
+		port := group.Port + channel
+		return fmt.Sprintf("RTP/AVP;multicast;destination=%s;port=%d-%d;ttl=%d", group.IP, port, port+1, ttl), nil
+	}
+
+	// RTP/AVP;unicast;client_port=5000-5001
+	s1, s2, _ := strings.Cut(core.Between(tr, "client_port=", ";"), "-")
+	port1 := core.Atoi(s1)
+	port2 := core.Atoi(s2)
+	if port1 <= 0 {
+		return "", errors.New("rtsp: wrong client_port: " + tr)
+	}
+	if port2 <= 0 {
+		port2 = port1 + 1
+	}
+
+	remoteAddr, ok := c.conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return "", errors.New("rtsp: UDP transport only for TCP connections")
+	}
+
+	conn1, conn2, err := ListenUDPPair()
+	if err != nil {
+		return "", err
+	}
+
+	c.stateMu.Lock()
+	for len(c.udpConn) < channel+2 {
+		c.udpConn = append(c.udpConn, nil)
+		c.udpAddr = append(c.udpAddr, nil)
+	}
+
+	c.udpConn[channel] = conn1
+	c.udpConn[channel+1] = conn2
+	c.udpAddr[channel] = &net.UDPAddr{IP: remoteAddr.IP, Port: port1}
+	c.udpAddr[channel+1] = &net.UDPAddr{IP: remoteAddr.IP, Port: port2}
+	c.stateMu.Unlock()
+
+	c.setTransport(i, "udp")
+
+	port := conn1.LocalAddr().(*net.UDPAddr).Port
+	return fmt.Sprintf("RTP/AVP;unicast;client_port=%d-%d;server_port=%d-%d", port1, port2, port, port+1), nil
+}
+
+func (c *Conn) setState(state State) {
+	c.stateMu.Lock()
+	c.state = state
+	c.stateMu.Unlock()
+}
+
+// setTransport - save transport for server consumer track
+func (c *Conn) setTransport(i int, transport string) {
+	c.stateMu.Lock()
+	for len(c.transports) <= i {
+		c.transports = append(c.transports, "")
+	}
+	c.transports[i] = transport
+	c.stateMu.Unlock()
+}
+
+// transportProtocol - protocol with all used track transports, ex. rtsp+tcp+udp
+func (c *Conn) transportProtocol() string {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	protocol, _, _ := strings.Cut(c.Protocol, "+")
+	for _, tr := range []string{"tcp", "udp", "multicast"} {
+		if slices.Contains(c.transports, tr) {
+			protocol += "+" + tr
+		}
+	}
+	if !strings.Contains(protocol, "+") {
+		return c.Protocol
+	}
+	return protocol
+}
+
 func reqTrackID(req *tcp.Request) int {
 	var s string
 	if req.URL.RawQuery != "" {
@@ -233,3 +381,11 @@ func reqTrackID(req *tcp.Request) int {
 	}
 	return -1
 }
+
+func (c *Conn) writeForbidden(req *tcp.Request) error {
+	res := &tcp.Response{Status: "403 Forbidden", Request: req}
+	if err := c.WriteResponse(res); err != nil {
+		return err
+	}
+	return Forbidden
+}
@@ -0,0 +1,121 @@
+package rtsp
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AlexxIT/go2rtc/pkg/core"
+	"github.com/AlexxIT/go2rtc/pkg/tcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerUDP(t *testing.T) {
+	Timeout = 5 * time.Second
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.Nil(t, err)
+
+	codec := &core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: 96}
+	track := core.NewReceiver(&core.Media{Kind: core.KindVideo, Direction: core.DirectionRecvonly}, codec)
+
+	errs := make(chan error, 1)
+
+	// wait for the server, so it doesn't run in the next tests
+	closed := make(chan struct{})
+	defer func() { <-closed }()
+	defer ln.Close()
+
+	go func() {
+		defer close(closed)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		server := NewServer(conn)
+		server.Listen(func(msg any) {
+			if msg == MethodDescribe {
+				media := &core.Media{Kind: core.KindVideo, Direction: core.DirectionSendonly, Codecs: []*core.Codec{codec}}
+				server.Medias = []*core.Media{media}
+				_ = server.AddTrack(media, codec, track)
+			}
+		})
+
+		if err = server.Accept(); err == nil {
+			_ = server.Handle()
+		}
+	}()
+
+	client := NewClient("rtsp://" + ln.Addr().String() + "/stream")
+	client.Transport = "udp"
+
+	require.Nil(t, client.Dial())
+	require.Nil(t, client.Describe())
+	require.Len(t, client.Medias, 1)
+
+	media := client.Medias[0]
+	receiver, err := client.GetTrack(media, media.Codecs[0])
+	require.Nil(t, err)
+
+	packets := make(chan *rtp.Packet, 10)
+	sender := core.NewSender(media, media.Codecs[0])
+	sender.Handler = func(packet *rtp.Packet) {
+		packets <- packet
+	}
+	sender.HandleRTP(receiver)
+
+	go func() {
+		_ = client.Start()
+	}()
+	defer client.Stop()
+
+	// repeat packets until PLAY will be processed by the server
+	for i := uint16(0); ; i++ {
+		track.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: i, Marker: true}, Payload: []byte{0x65, 0x00}})
+
+		select {
+		case packet := <-packets:
+			require.Equal(t, []byte{0x65, 0x00}, packet.Payload)
+			require.Equal(t, "rtsp+udp", client.Protocol)
+			return
+		case err = <-errs:
+			require.Nil(t, err)
+		case <-time.After(10 * time.Millisecond):
+			require.Less(t, i, uint16(300), "no UDP packets")
+		}
+	}
+}
+
+func TestServerMulticast(t *testing.T) {
+	media := &core.Media{Kind: core.KindVideo, Direction: core.DirectionSendonly}
+	codec := &core.Codec{Name: core.CodecH264, ClockRate: 90000, PayloadType: 96}
+
+	c := &Conn{}
+	c.Senders = []*core.Sender{core.NewSender(media, codec), core.NewSender(media, codec)}
+
+	u, _ := url.Parse("rtsp://localhost/stream/trackID=1")
+	req := &tcp.Request{URL: u}
+
+	_, err := c.setupUDP(req, "RTP/AVP;multicast")
+	require.NotNil(t, err)
+
+	c.OnMulticast = func() (*net.UDPAddr, int, error) {
+		return &net.UDPAddr{IP: net.IPv4(239, 0, 0, 1), Port: 5000}, 1, nil
+	}
+
+	tr, err := c.setupUDP(req, "RTP/AVP;multicast")
+	require.Nil(t, err)
+	require.Equal(t, "RTP/AVP;multicast;destination=239.0.0.1;port=5002-5003;ttl=1", tr)
+
+	// transport is stored per track, so TCP track stays on TCP
+	c.Protocol = "rtsp+tcp"
+	c.setTransport(0, "tcp")
+	require.Equal(t, "tcp", c.trackTransport(0))
+	require.Equal(t, "multicast", c.trackTransport(2))
+	require.Equal(t, "rtsp+tcp+multicast", c.transportProtocol())
+}
@@ -2,7 +2,11 @@ package shell
 
 import (
 	"context"
+	"maps"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 )
 
 // Command like exec.Cmd, but with support:
@@ -29,9 +33,22 @@ func (c *Command) Start() error {
 		return err
 	}
 
+	name := filepath.Base(c.Path)
+	name = strings.TrimSuffix(name, filepath.Ext(name)) // ffmpeg.exe => ffmpeg
+
+	runningMu.Lock()
+	running[name]++
+	runningMu.Unlock()
+
 	go func() {
 		c.err = c.Cmd.Wait()
 		c.cancel() // release context resources
+
+		runningMu.Lock()
+		if running[name]--; running[name] == 0 {
+			delete(running, name)
+		}
+		runningMu.Unlock()
 	}()
 
 	return nil
@@ -57,3 +74,14 @@ func (c *Command) Close() error {
 	c.cancel()
 	return nil
 }
+
+var running = map[string]int{}
+var runningMu sync.Mutex
+
+// Running return number of running processes by binary name
+func Running() map[string]int {
+	runningMu.Lock()
+	defer runningMu.Unlock()
+
+	return maps.Clone(running)
+}
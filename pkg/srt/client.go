@@ -0,0 +1,234 @@
+package srt
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const handshakeTimeout = 5 * time.Second
+
+// Dial - connect in the caller mode:
+//   - srt://192.168.1.123:9000?streamid=camera1
+//   - srt://192.168.1.123:9000?streamid=camera1&passphrase=secret123456&pbkeylen=32&latency=200
+//
+// Latency in milliseconds, passphrase from 10 to 79 characters, pbkeylen 16 (default), 24 or 32
+func Dial(rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+
+	if mode := query.Get("mode"); mode != "" && mode != "caller" {
+		return nil, errors.New("srt: unsupported mode: " + mode)
+	}
+
+	latency := DefaultLatency
+	if s := query.Get("latency"); s != "" {
+		ms, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, errors.New("srt: wrong latency: " + s)
+		}
+		latency = time.Duration(ms) * time.Millisecond
+	}
+
+	passphrase := query.Get("passphrase")
+
+	var km *keyMaterial
+	if passphrase != "" {
+		if err = checkPassphrase(passphrase); err != nil {
+			return nil, err
+		}
+
+		keyLen := 16
+		if s := query.Get("pbkeylen"); s != "" {
+			if keyLen, err = parseKeyLen(s); err != nil {
+				return nil, err
+			}
+		}
+
+		if km, err = newKeyMaterial(keyLen); err != nil {
+			return nil, err
+		}
+	}
+
+	conn, err := net.DialTimeout("udp", u.Host, handshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.(*net.UDPConn).SetReadBuffer(1 << 20)
+
+	c, err := handshakeCaller(conn.(*net.UDPConn), query.Get("streamid"), passphrase, latency, km)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func handshakeCaller(conn *net.UDPConn, streamID, passphrase string, latency time.Duration, km *keyMaterial) (*Conn, error) {
+	localID := randSocketID()
+	initialSeq := randUint32() & seqMask
+
+	req := &handshake{
+		version:    4,
+		extension:  2, // UDT_DGRAM
+		initialSeq: initialSeq,
+		mtu:        defaultMTU,
+		flowWindow: defaultFlowWindow,
+		hsType:     hsTypeInduction,
+		socketID:   localID,
+	}
+
+	res, err := handshakeRequest(conn, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.version != 5 || res.extension != hsMagicCode {
+		return nil, errors.New("srt: unsupported handshake version: " + strconv.Itoa(int(res.version)))
+	}
+
+	req.version = 5
+	req.extension = hsExtHSREQ
+	req.hsType = hsTypeConclusion
+	req.cookie = res.cookie
+	req.srtVersion = srtVersion
+	req.srtFlags = srtFlags
+	req.recvDelay = uint16(latency.Milliseconds())
+	req.sendDelay = uint16(latency.Milliseconds())
+
+	if km != nil {
+		if req.km, err = km.Marshal(passphrase); err != nil {
+			return nil, err
+		}
+		req.encryption = uint16(len(km.keys[0]) / 8)
+		req.extension |= hsExtKMREQ
+	}
+
+	if streamID != "" {
+		req.streamID = streamID
+		req.extension |= hsExtConfig
+	}
+
+	if res, err = handshakeRequest(conn, req); err != nil {
+		return nil, err
+	}
+
+	if res.hsType != hsTypeConclusion {
+		return nil, RejectError(res.hsType)
+	}
+
+	if km != nil && len(res.km) <= 4 {
+		return nil, errBadSecret // KMRSP with the error state
+	}
+
+	latency = max(latency, time.Duration(res.recvDelay)*time.Millisecond, time.Duration(res.sendDelay)*time.Millisecond)
+
+	c := newConn(localID, res.socketID, initialSeq, conn.RemoteAddr(), latency, km)
+	c.StreamID = streamID
+	c.pass = passphrase
+	c.write = func(b []byte) error {
+		_, err := conn.Write(b)
+		return err
+	}
+	c.onClose = func() {
+		_ = conn.Close()
+	}
+
+	go c.runTimers()
+
+	go func() {
+		b := make([]byte, defaultMTU)
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				c.close(err)
+				return
+			}
+			c.handle(b[:n])
+		}
+	}()
+
+	return c, nil
+}
+
+// handshakeRequest - send request until handshake response or timeout
+func handshakeRequest(conn *net.UDPConn, req *handshake) (*handshake, error) {
+	pkt := &packet{control: true, ctrlType: ctrlHandshake, payload: req.Marshal()}
+	b := pkt.Marshal()
+
+	deadline := time.Now().Add(handshakeTimeout)
+	defer conn.SetReadDeadline(time.Time{})
+
+	buf := make([]byte, defaultMTU)
+
+	for time.Now().Before(deadline) {
+		if _, err := conn.Write(b); err != nil {
+			return nil, err
+		}
+
+		_ = conn.SetReadDeadline(time.Now().Add(250 * time.Millisecond))
+
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				if ne, ok := err.(net.Error); ok && ne.Timeout() {
+					break // repeat request
+				}
+				return nil, err
+			}
+
+			if err = pkt.Unmarshal(buf[:n]); err != nil || !pkt.control || pkt.ctrlType != ctrlHandshake {
+				continue
+			}
+
+			res := &handshake{}
+			if err = res.Unmarshal(pkt.payload); err != nil {
+				return nil, err
+			}
+
+			if res.hsType == req.hsType || res.hsType >= RejectUnknown && res.hsType != hsTypeConclusion {
+				return res, nil
+			}
+		}
+	}
+
+	return nil, errors.New("srt: handshake timeout")
+}
+
+func checkPassphrase(passphrase string) error {
+	if n := len(passphrase); n < 10 || n > 79 {
+		return errors.New("srt: passphrase must be from 10 to 79 characters")
+	}
+	return nil
+}
+
+func parseKeyLen(s string) (int, error) {
+	switch s {
+	case "0", "16":
+		return 16, nil
+	case "24", "32":
+		return strconv.Atoi(s)
+	}
+	return 0, errors.New("srt: wrong pbkeylen: " + s)
+}
+
+// randSocketID - non zero socket ID, zero is used for the handshake with listener
+func randSocketID() uint32 {
+	return randUint32()&0x3FFFFFFF | 1
+}
+
+func randUint32() uint32 {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return binary.BigEndian.Uint32(b)
+}
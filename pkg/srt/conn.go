@@ -0,0 +1,522 @@
+package srt
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	ackInterval       = 10 * time.Millisecond
+	keepaliveInterval = time.Second
+	peerIdleTimeout   = 5 * time.Second
+	minNAKInterval    = 20 * time.Millisecond
+
+	defaultMTU        = 1500
+	defaultFlowWindow = 8192
+	readQueueSize     = 2048
+)
+
+// DefaultLatency - TSBPD delay, same as libsrt default
+const DefaultLatency = 120 * time.Millisecond
+
+// Conn - SRT connection in the live mode, used as io.ReadWriteCloser
+// for the MPEG-TS payload, ex. 7 TS packets per SRT packet
+type Conn struct {
+	StreamID string
+
+	localID uint32
+	peerID  uint32
+	remote  net.Addr
+	latency time.Duration
+	km      *keyMaterial // keys for sending
+	recvKM  *keyMaterial // keys for receiving, can be refreshed by the peer
+	pass    string
+	start   time.Time
+
+	write    func(b []byte) error // send UDP datagram to the peer
+	onClose  func()
+	response []byte // listener handshake response for the retransmitted requests
+
+	mu sync.Mutex
+
+	// sender state
+	sendSeq  uint32
+	msgNo    uint32
+	sendBuf  []*sendPacket // continuous sequence numbers, from the oldest not acknowledged
+	lastSend time.Time
+	lastACK  time.Time
+
+	// receiver state
+	recvSeq  uint32 // next sequence for the reader
+	recvNext uint32 // next sequence after the highest received
+	recvBuf  map[uint32]*recvPacket
+	lastRecv time.Time
+	ackSeq   uint32
+	ackNo    uint32
+	ackTimes map[uint32]time.Time
+	lastNAK  time.Time
+
+	rtt    time.Duration
+	rttVar time.Duration
+
+	queue  chan []byte
+	buf    []byte // unread part of the last payload
+	done   chan struct{}
+	closed bool
+	err    error
+
+	// statistics
+	Recv int
+	Send int
+}
+
+type sendPacket struct {
+	pkt  *packet
+	time time.Time
+}
+
+type recvPacket struct {
+	payload []byte
+	time    time.Time
+}
+
+func newConn(localID, peerID, initialSeq uint32, remote net.Addr, latency time.Duration, km *keyMaterial) *Conn {
+	now := time.Now()
+	return &Conn{
+		localID:  localID,
+		peerID:   peerID,
+		remote:   remote,
+		latency:  latency,
+		km:       km,
+		recvKM:   km,
+		start:    now,
+		sendSeq:  initialSeq,
+		recvSeq:  initialSeq,
+		recvNext: initialSeq,
+		ackSeq:   initialSeq,
+		recvBuf:  map[uint32]*recvPacket{},
+		ackTimes: map[uint32]time.Time{},
+		lastRecv: now,
+		lastSend: now,
+		lastACK:  now,
+		rtt:      100 * time.Millisecond,
+		rttVar:   50 * time.Millisecond,
+		queue:    make(chan []byte, readQueueSize),
+		done:     make(chan struct{}),
+	}
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func (c *Conn) Latency() time.Duration {
+	return c.latency
+}
+
+// Read - payload of data packets in the sequence order, lost packets are skipped after the latency
+func (c *Conn) Read(b []byte) (int, error) {
+	if len(c.buf) == 0 {
+		select {
+		case c.buf = <-c.queue:
+		case <-c.done:
+			select {
+			case c.buf = <-c.queue:
+			default:
+				return 0, c.closeErr()
+			}
+		}
+	}
+
+	n := copy(b, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// Write - split data to the packets with PayloadSize
+func (c *Conn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return 0, c.errLocked()
+	}
+
+	n := len(b)
+
+	for len(b) > 0 {
+		size := min(len(b), PayloadSize)
+
+		payload := make([]byte, size)
+		copy(payload, b)
+		b = b[size:]
+
+		pkt := &packet{
+			seq:       c.sendSeq,
+			flags:     flagSingle | c.msgNo&msgNoMask,
+			timestamp: c.timestamp(),
+			socketID:  c.peerID,
+			payload:   payload,
+		}
+
+		if c.km != nil {
+			pkt.flags |= keyEven << 27
+			if err := c.km.xor(keyEven, pkt.seq, payload); err != nil {
+				return 0, err
+			}
+		}
+
+		c.sendSeq = seqNext(c.sendSeq)
+		c.msgNo = (c.msgNo + 1) & msgNoMask
+		c.sendBuf = append(c.sendBuf, &sendPacket{pkt: pkt, time: time.Now()})
+
+		if err := c.send(pkt); err != nil {
+			return 0, err
+		}
+	}
+
+	c.Send += n
+
+	return n, nil
+}
+
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	if !c.closed {
+		_ = c.send(c.control(ctrlShutdown, 0, make([]byte, 4)))
+		c.closeLocked(nil)
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Conn) close(err error) {
+	c.mu.Lock()
+	c.closeLocked(err)
+	c.mu.Unlock()
+}
+
+func (c *Conn) closeLocked(err error) {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.err = err
+	close(c.done)
+
+	if c.onClose != nil {
+		go c.onClose()
+	}
+}
+
+func (c *Conn) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.errLocked()
+}
+
+func (c *Conn) errLocked() error {
+	if c.err != nil {
+		return c.err
+	}
+	return io.EOF
+}
+
+func (c *Conn) timestamp() uint32 {
+	return uint32(time.Since(c.start).Microseconds())
+}
+
+func (c *Conn) control(ctrlType uint16, info uint32, payload []byte) *packet {
+	return &packet{control: true, ctrlType: ctrlType, info: info, socketID: c.peerID, payload: payload}
+}
+
+func (c *Conn) send(pkt *packet) error {
+	if pkt.control {
+		pkt.timestamp = c.timestamp()
+	}
+	c.lastSend = time.Now()
+	return c.write(pkt.Marshal())
+}
+
+// handle - process packet from the UDP socket, the buffer can be reused after return
+func (c *Conn) handle(b []byte) {
+	pkt := &packet{}
+	if err := pkt.Unmarshal(b); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	c.lastRecv = time.Now()
+
+	if !pkt.control {
+		c.handleData(pkt)
+		return
+	}
+
+	switch pkt.ctrlType {
+	case ctrlACK:
+		c.handleACK(pkt)
+	case ctrlNAK:
+		c.handleNAK(pkt)
+	case ctrlACKACK:
+		c.handleACKACK(pkt)
+	case ctrlShutdown:
+		c.closeLocked(errors.New("srt: connection closed by peer"))
+	case ctrlUser:
+		if pkt.subtype == extKMREQ {
+			c.handleKMREQ(pkt)
+		}
+	}
+}
+
+// handleKMREQ - peer sends new keys before switching to them (libsrt km_refreshrate)
+func (c *Conn) handleKMREQ(pkt *packet) {
+	if c.pass == "" {
+		return
+	}
+
+	km, err := parseKeyMaterial(pkt.payload, c.pass)
+	if err != nil {
+		return
+	}
+
+	c.recvKM = km
+
+	res := c.control(ctrlUser, 0, append([]byte(nil), pkt.payload...))
+	res.subtype = extKMRSP
+	_ = c.send(res)
+}
+
+func (c *Conn) handleData(pkt *packet) {
+	if d := seqDiff(pkt.seq, c.recvSeq); d < -defaultFlowWindow || d > defaultFlowWindow {
+		// sequence out of the receiver window, start from this packet
+		c.recvBuf = map[uint32]*recvPacket{}
+		c.recvSeq = pkt.seq
+		c.recvNext = pkt.seq
+	} else if d < 0 {
+		return // late or duplicate packet
+	}
+	if _, ok := c.recvBuf[pkt.seq]; ok {
+		return // duplicate packet
+	}
+
+	payload := make([]byte, len(pkt.payload))
+	copy(payload, pkt.payload)
+
+	if keys := pkt.keys(); keys != 0 {
+		if c.recvKM == nil || c.recvKM.xor(keys, pkt.seq, payload) != nil {
+			return // can't decrypt packet
+		}
+	}
+
+	c.recvBuf[pkt.seq] = &recvPacket{payload: payload, time: time.Now()}
+	c.Recv += len(payload)
+
+	if d := seqDiff(pkt.seq, c.recvNext); d >= 0 {
+		if d > 0 {
+			// immediate report about the new lost packets
+			var lost []uint32
+			for seq := c.recvNext; seq != pkt.seq && len(lost) < 1000; seq = seqNext(seq) {
+				lost = append(lost, seq)
+			}
+			_ = c.send(c.control(ctrlNAK, 0, marshalLossList(lost)))
+		}
+		c.recvNext = seqNext(pkt.seq)
+	}
+
+	c.deliver()
+}
+
+// deliver - move continuous packets to the reader queue
+func (c *Conn) deliver() {
+	for {
+		recv, ok := c.recvBuf[c.recvSeq]
+		if !ok {
+			return
+		}
+
+		delete(c.recvBuf, c.recvSeq)
+		c.recvSeq = seqNext(c.recvSeq)
+
+		select {
+		case c.queue <- recv.payload:
+		default:
+			// slow reader, drop the packet like a lost one
+		}
+	}
+}
+
+func (c *Conn) handleACK(pkt *packet) {
+	if len(pkt.payload) < 4 {
+		return
+	}
+
+	ackSeq := binary.BigEndian.Uint32(pkt.payload) & seqMask
+
+	c.lastACK = time.Now()
+
+	// receiver measures RTT with ACKACK
+	if len(pkt.payload) >= 12 {
+		c.rtt = time.Duration(binary.BigEndian.Uint32(pkt.payload[4:])) * time.Microsecond
+		c.rttVar = time.Duration(binary.BigEndian.Uint32(pkt.payload[8:])) * time.Microsecond
+	}
+
+	// remove acknowledged packets from the sender buffer
+	i := 0
+	for ; i < len(c.sendBuf); i++ {
+		if seqDiff(c.sendBuf[i].pkt.seq, ackSeq) >= 0 {
+			break
+		}
+	}
+	c.sendBuf = c.sendBuf[i:]
+
+	// full ACK must be confirmed with ACKACK (light ACK has only the sequence number)
+	if len(pkt.payload) >= 16 {
+		_ = c.send(c.control(ctrlACKACK, pkt.info, make([]byte, 4)))
+	}
+}
+
+func (c *Conn) handleNAK(pkt *packet) {
+	if len(c.sendBuf) == 0 {
+		return
+	}
+
+	first := c.sendBuf[0].pkt.seq
+
+	for _, seq := range unmarshalLossList(pkt.payload) {
+		i := int(seqDiff(seq, first))
+		if i < 0 || i >= len(c.sendBuf) {
+			continue // packet already acknowledged or dropped
+		}
+
+		retransmit := *c.sendBuf[i].pkt
+		retransmit.flags |= flagRetransmit
+		_ = c.send(&retransmit)
+	}
+}
+
+func (c *Conn) handleACKACK(pkt *packet) {
+	sent, ok := c.ackTimes[pkt.info]
+	if !ok {
+		return
+	}
+
+	delete(c.ackTimes, pkt.info)
+
+	rtt := time.Since(sent)
+	c.rttVar = (c.rttVar*3 + (c.rtt - rtt).Abs()) / 4
+	c.rtt = (c.rtt*7 + rtt) / 8
+}
+
+// tick - timers for ACK, NAK, packets drop and keepalive, should be called every ackInterval
+func (c *Conn) tick(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	if now.Sub(c.lastRecv) > peerIdleTimeout {
+		c.closeLocked(errors.New("srt: connection timeout"))
+		return false
+	}
+
+	// too late packet drop: skip lost packets when next packets wait longer than latency
+	if len(c.recvBuf) > 0 {
+		for seq := c.recvSeq; seq != c.recvNext; seq = seqNext(seq) {
+			if recv, ok := c.recvBuf[seq]; ok {
+				if now.Sub(recv.time) > c.latency {
+					c.recvSeq = seq
+					c.deliver()
+				}
+				break
+			}
+		}
+	}
+
+	if c.ackSeq != c.recvSeq {
+		c.ackSeq = c.recvSeq
+		c.ackNo++
+		c.ackTimes[c.ackNo] = now
+
+		// remove old ACK times without ACKACK
+		for ackNo, sent := range c.ackTimes {
+			if now.Sub(sent) > time.Second {
+				delete(c.ackTimes, ackNo)
+			}
+		}
+
+		b := make([]byte, 28)
+		binary.BigEndian.PutUint32(b, c.recvSeq)
+		binary.BigEndian.PutUint32(b[4:], uint32(c.rtt.Microseconds()))
+		binary.BigEndian.PutUint32(b[8:], uint32(c.rttVar.Microseconds()))
+		binary.BigEndian.PutUint32(b[12:], uint32(readQueueSize-len(c.queue)))
+		_ = c.send(c.control(ctrlACK, c.ackNo, b))
+	}
+
+	// periodic NAK report for all lost packets
+	// should be several times during the latency, even before RTT measurement
+	nakInterval := min(max((c.rtt+4*c.rttVar)/2, minNAKInterval), c.latency/4)
+	if c.recvSeq != c.recvNext && now.Sub(c.lastNAK) > nakInterval {
+		c.lastNAK = now
+
+		var lost []uint32
+		for seq := c.recvSeq; seq != c.recvNext && len(lost) < 1000; seq = seqNext(seq) {
+			if _, ok := c.recvBuf[seq]; !ok {
+				lost = append(lost, seq)
+			}
+		}
+		if lost != nil {
+			_ = c.send(c.control(ctrlNAK, 0, marshalLossList(lost)))
+		}
+	}
+
+	// sender drop: packets can't be useful for the receiver after the latency
+	i := 0
+	for ; i < len(c.sendBuf); i++ {
+		if now.Sub(c.sendBuf[i].time) < c.latency+time.Second {
+			break
+		}
+	}
+	c.sendBuf = c.sendBuf[i:]
+
+	// retransmit not acknowledged packets on the ACK timeout, ex. lost tail of the stream
+	if rto := c.rtt + 4*c.rttVar + ackInterval; len(c.sendBuf) > 0 && now.Sub(c.lastACK) > rto {
+		c.lastACK = now
+		for _, sent := range c.sendBuf {
+			if now.Sub(sent.time) > rto {
+				retransmit := *sent.pkt
+				retransmit.flags |= flagRetransmit
+				_ = c.send(&retransmit)
+			}
+		}
+	}
+
+	if now.Sub(c.lastSend) > keepaliveInterval {
+		_ = c.send(c.control(ctrlKeepalive, 0, make([]byte, 4)))
+	}
+
+	return true
+}
+
+func (c *Conn) runTimers() {
+	ticker := time.NewTicker(ackInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		if !c.tick(now) {
+			return
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package srt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	kmSaltSize   = 16
+	kmIterations = 2048
+	kmCipherCTR  = 2
+	kmSE         = 2 // stream encapsulation: MPEG-TS/SRT
+)
+
+var errBadSecret = errors.New("srt: wrong passphrase")
+
+// keyMaterial - stream encrypting keys (SEK) with the salt,
+// exchanged in KMREQ/KMRSP extensions and wrapped with the passphrase
+type keyMaterial struct {
+	salt  []byte
+	even  cipher.Block
+	odd   cipher.Block
+	keys  [2][]byte
+	flags byte // keyEven and/or keyOdd
+}
+
+func newKeyMaterial(keyLen int) (*keyMaterial, error) {
+	km := &keyMaterial{salt: make([]byte, kmSaltSize), flags: keyEven}
+	km.keys[0] = make([]byte, keyLen)
+
+	if _, err := rand.Read(km.salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(km.keys[0]); err != nil {
+		return nil, err
+	}
+
+	return km, km.init()
+}
+
+func (km *keyMaterial) init() (err error) {
+	if km.flags&keyEven != 0 {
+		if km.even, err = aes.NewCipher(km.keys[0]); err != nil {
+			return
+		}
+	}
+	if km.flags&keyOdd != 0 {
+		if km.odd, err = aes.NewCipher(km.keys[1]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (km *keyMaterial) Marshal(passphrase string) ([]byte, error) {
+	var keys []byte
+	if km.flags&keyEven != 0 {
+		keys = append(keys, km.keys[0]...)
+	}
+	if km.flags&keyOdd != 0 {
+		keys = append(keys, km.keys[1]...)
+	}
+
+	kek, err := deriveKEK(passphrase, km.salt, len(km.keys[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := keyWrap(kek, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	b := []byte{
+		0x12, 0x20, 0x29, km.flags, // version 1, packet type 2 (KM), signature "HAI", keys
+		0, 0, 0, 0, // KEKI
+		kmCipherCTR, 0, kmSE, 0,
+		0, 0, kmSaltSize / 4, byte(len(km.keys[0]) / 4),
+	}
+	b = append(b, km.salt...)
+	return append(b, wrapped...), nil
+}
+
+func parseKeyMaterial(b []byte, passphrase string) (*keyMaterial, error) {
+	if len(b) < 16 || b[0] != 0x12 || b[1] != 0x20 || b[2] != 0x29 || b[8] != kmCipherCTR {
+		return nil, errors.New("srt: unsupported key material")
+	}
+
+	km := &keyMaterial{flags: b[3] & 0b11}
+	saltLen := int(b[14]) * 4
+	keyLen := int(b[15]) * 4
+
+	n := 0
+	if km.flags&keyEven != 0 {
+		n++
+	}
+	if km.flags&keyOdd != 0 {
+		n++
+	}
+
+	// KEK uses last 8 bytes of the salt and the CTR IV uses first 14 bytes
+	if saltLen != kmSaltSize || keyLen != 16 && keyLen != 24 && keyLen != 32 {
+		return nil, errors.New("srt: wrong key material")
+	}
+
+	if n == 0 || len(b) < 16+saltLen+8+n*keyLen {
+		return nil, errors.New("srt: wrong key material")
+	}
+
+	km.salt = append([]byte(nil), b[16:16+saltLen]...)
+
+	kek, err := deriveKEK(passphrase, km.salt, keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := keyUnwrap(kek, b[16+saltLen:16+saltLen+8+n*keyLen])
+	if err != nil {
+		return nil, err
+	}
+
+	if km.flags&keyEven != 0 {
+		km.keys[0], keys = keys[:keyLen], keys[keyLen:]
+	}
+	if km.flags&keyOdd != 0 {
+		km.keys[1] = keys[:keyLen]
+	}
+
+	return km, km.init()
+}
+
+// xor - encrypt or decrypt payload of data packet with AES-CTR
+func (km *keyMaterial) xor(keys byte, seq uint32, payload []byte) error {
+	var block cipher.Block
+	switch keys {
+	case keyEven:
+		block = km.even
+	case keyOdd:
+		block = km.odd
+	}
+	if block == nil {
+		return errors.New("srt: no key for packet")
+	}
+
+	// IV: salt[0:14] XOR packet index at 10..13, block counter at 14..15
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, km.salt[:14])
+	binary.BigEndian.PutUint32(iv[10:], binary.BigEndian.Uint32(iv[10:])^seq)
+
+	cipher.NewCTR(block, iv).XORKeyStream(payload, payload)
+	return nil
+}
+
+// deriveKEK - key encrypting key from the passphrase and last 8 bytes of the salt
+func deriveKEK(passphrase string, salt []byte, keyLen int) ([]byte, error) {
+	return pbkdf2.Key(sha1.New, passphrase, salt[len(salt)-8:], kmIterations, keyLen)
+}
+
+var keyWrapIV = []byte{0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6, 0xA6}
+
+// keyWrap - AES key wrap, RFC 3394
+func keyWrap(kek, plain []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(plain) / 8
+	out := make([]byte, 8+len(plain))
+	copy(out, keyWrapIV)
+	copy(out[8:], plain)
+
+	b := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, out[:8])
+			copy(b[8:], out[i*8:])
+			block.Encrypt(b, b)
+
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out, binary.BigEndian.Uint64(b)^t)
+			copy(out[i*8:], b[8:])
+		}
+	}
+
+	return out, nil
+}
+
+func keyUnwrap(kek, wrapped []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(wrapped)/8 - 1
+	out := make([]byte, len(wrapped))
+	copy(out, wrapped)
+
+	b := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b, binary.BigEndian.Uint64(out)^t)
+			copy(b[8:], out[i*8:])
+			block.Decrypt(b, b)
+
+			copy(out, b[:8])
+			copy(out[i*8:], b[8:])
+		}
+	}
+
+	if subtle.ConstantTimeCompare(out[:8], keyWrapIV) != 1 {
+		return nil, errBadSecret
+	}
+
+	return out[8:], nil
+}
@@ -0,0 +1,203 @@
+package srt
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+const (
+	hsTypeInduction  = 1
+	hsTypeConclusion = 0xFFFFFFFF
+
+	hsMagicCode = 0x4A17 // extension field of the induction response for HSv5
+
+	hsExtHSREQ  = 0x1
+	hsExtKMREQ  = 0x2
+	hsExtConfig = 0x4
+
+	extHSREQ = 1
+	extHSRSP = 2
+	extKMREQ = 3
+	extKMRSP = 4
+	extSID   = 5
+
+	srtVersion = 0x00010501 // 1.5.1
+	srtFlags   = 0x3F       // TSBPD send and receive, crypt, packet drop, periodic NAK, retransmit flag
+
+	handshakeSize = 48
+)
+
+// Rejection reasons for the handshake type (1000 + reason)
+const (
+	RejectUnknown      = 1000
+	RejectBadSecret    = 1010
+	RejectUnsecure     = 1011
+	RejectBadRequest   = 2400
+	RejectUnauthorized = 2401
+	RejectForbidden    = 2403
+	RejectNotFound     = 2404
+	RejectBadMode      = 2405
+	RejectConflict     = 2409
+)
+
+var rejectReasons = map[uint32]string{
+	RejectBadSecret:    "wrong passphrase",
+	RejectUnsecure:     "password required or unexpected",
+	RejectBadRequest:   "bad request",
+	RejectUnauthorized: "unauthorized",
+	RejectForbidden:    "forbidden",
+	RejectNotFound:     "not found",
+	RejectBadMode:      "bad mode",
+	RejectConflict:     "conflict",
+}
+
+type RejectError uint32
+
+func (e RejectError) Error() string {
+	if s, ok := rejectReasons[uint32(e)]; ok {
+		return "srt: rejected: " + s
+	}
+	return "srt: rejected: " + strconv.Itoa(int(e))
+}
+
+type handshake struct {
+	version    uint32
+	encryption uint16 // key length / 8, only for HSv5 conclusion request
+	extension  uint16
+	initialSeq uint32
+	mtu        uint32
+	flowWindow uint32
+	hsType     uint32
+	socketID   uint32
+	cookie     uint32
+	peerIP     [16]byte
+
+	// HSREQ/HSRSP extension
+	srtVersion uint32
+	srtFlags   uint32
+	recvDelay  uint16 // TSBPD delay in milliseconds
+	sendDelay  uint16
+
+	km       []byte // key material message for KMREQ/KMRSP
+	streamID string
+
+	response bool // HSRSP and KMRSP extensions instead of requests
+}
+
+func (h *handshake) Marshal() []byte {
+	b := make([]byte, handshakeSize)
+	binary.BigEndian.PutUint32(b, h.version)
+	binary.BigEndian.PutUint16(b[4:], h.encryption)
+	binary.BigEndian.PutUint16(b[6:], h.extension)
+	binary.BigEndian.PutUint32(b[8:], h.initialSeq)
+	binary.BigEndian.PutUint32(b[12:], h.mtu)
+	binary.BigEndian.PutUint32(b[16:], h.flowWindow)
+	binary.BigEndian.PutUint32(b[20:], h.hsType)
+	binary.BigEndian.PutUint32(b[24:], h.socketID)
+	binary.BigEndian.PutUint32(b[28:], h.cookie)
+	copy(b[32:], h.peerIP[:])
+
+	if h.hsType != hsTypeConclusion || h.version != 5 {
+		return b
+	}
+
+	if h.srtVersion != 0 {
+		ext := make([]byte, 12)
+		binary.BigEndian.PutUint32(ext, h.srtVersion)
+		binary.BigEndian.PutUint32(ext[4:], h.srtFlags)
+		binary.BigEndian.PutUint16(ext[8:], h.recvDelay)
+		binary.BigEndian.PutUint16(ext[10:], h.sendDelay)
+
+		extType := uint16(extHSREQ)
+		if h.response {
+			extType = extHSRSP
+		}
+		b = appendExtension(b, extType, ext)
+	}
+
+	if h.km != nil {
+		extType := uint16(extKMREQ)
+		if h.response {
+			extType = extKMRSP
+		}
+		b = appendExtension(b, extType, h.km)
+	}
+
+	if h.streamID != "" {
+		b = appendExtension(b, extSID, swapWords([]byte(h.streamID)))
+	}
+
+	return b
+}
+
+func (h *handshake) Unmarshal(b []byte) error {
+	if len(b) < handshakeSize {
+		return errors.New("srt: handshake too short")
+	}
+
+	h.version = binary.BigEndian.Uint32(b)
+	h.encryption = binary.BigEndian.Uint16(b[4:])
+	h.extension = binary.BigEndian.Uint16(b[6:])
+	h.initialSeq = binary.BigEndian.Uint32(b[8:])
+	h.mtu = binary.BigEndian.Uint32(b[12:])
+	h.flowWindow = binary.BigEndian.Uint32(b[16:])
+	h.hsType = binary.BigEndian.Uint32(b[20:])
+	h.socketID = binary.BigEndian.Uint32(b[24:])
+	h.cookie = binary.BigEndian.Uint32(b[28:])
+	copy(h.peerIP[:], b[32:])
+
+	if h.hsType != hsTypeConclusion || h.version != 5 {
+		return nil
+	}
+
+	for b = b[handshakeSize:]; len(b) >= 4; {
+		extType := binary.BigEndian.Uint16(b)
+		size := 4 * int(binary.BigEndian.Uint16(b[2:]))
+		if len(b) < 4+size {
+			return errors.New("srt: wrong handshake extension")
+		}
+
+		ext := b[4 : 4+size]
+		b = b[4+size:]
+
+		switch extType {
+		case extHSREQ, extHSRSP:
+			if len(ext) < 12 {
+				return errors.New("srt: wrong handshake extension")
+			}
+			h.srtVersion = binary.BigEndian.Uint32(ext)
+			h.srtFlags = binary.BigEndian.Uint32(ext[4:])
+			h.recvDelay = binary.BigEndian.Uint16(ext[8:])
+			h.sendDelay = binary.BigEndian.Uint16(ext[10:])
+		case extKMREQ, extKMRSP:
+			h.km = ext
+		case extSID:
+			sid := swapWords(ext)
+			for len(sid) > 0 && sid[len(sid)-1] == 0 {
+				sid = sid[:len(sid)-1]
+			}
+			h.streamID = string(sid)
+		}
+	}
+
+	return nil
+}
+
+func appendExtension(b []byte, extType uint16, ext []byte) []byte {
+	size := (len(ext) + 3) / 4
+	b = binary.BigEndian.AppendUint16(b, extType)
+	b = binary.BigEndian.AppendUint16(b, uint16(size))
+	b = append(b, ext...)
+	return append(b, make([]byte, size*4-len(ext))...)
+}
+
+// swapWords - stream ID is sent as 32-bit little endian words
+func swapWords(b []byte) []byte {
+	dst := make([]byte, (len(b)+3)/4*4)
+	copy(dst, b)
+	for i := 0; i < len(dst); i += 4 {
+		dst[i], dst[i+1], dst[i+2], dst[i+3] = dst[i+3], dst[i+2], dst[i+1], dst[i]
+	}
+	return dst
+}
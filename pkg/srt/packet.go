@@ -0,0 +1,139 @@
+package srt
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Control packet types
+const (
+	ctrlHandshake = 0x0000
+	ctrlKeepalive = 0x0001
+	ctrlACK       = 0x0002
+	ctrlNAK       = 0x0003
+	ctrlShutdown  = 0x0005
+	ctrlACKACK    = 0x0006
+	ctrlUser      = 0x7FFF // subtype KMREQ/KMRSP for the keys refresh
+)
+
+const headerSize = 16
+
+// PayloadSize - 7 MPEG-TS packets, default for live mode
+const PayloadSize = 1316
+
+// Data packet flags in the second header word
+const (
+	flagSingle     = 0b11 << 30 // packet position: solo packet of the message
+	flagRetransmit = 1 << 26
+	keyEven        = 0b01
+	keyOdd         = 0b10
+	msgNoMask      = 1<<26 - 1
+	seqMask        = 1<<31 - 1
+)
+
+type packet struct {
+	control bool
+
+	// data packet
+	seq   uint32
+	flags uint32 // position, order, key and retransmit flags with message number
+
+	// control packet
+	ctrlType uint16
+	subtype  uint16
+	info     uint32 // type specific information, ex. ACK number
+
+	timestamp uint32
+	socketID  uint32 // destination socket
+	payload   []byte
+}
+
+func (p *packet) keys() byte {
+	return byte(p.flags>>27) & 0b11
+}
+
+func (p *packet) Marshal() []byte {
+	b := make([]byte, headerSize+len(p.payload))
+	if p.control {
+		binary.BigEndian.PutUint16(b, 0x8000|p.ctrlType)
+		binary.BigEndian.PutUint16(b[2:], p.subtype)
+		binary.BigEndian.PutUint32(b[4:], p.info)
+	} else {
+		binary.BigEndian.PutUint32(b, p.seq&seqMask)
+		binary.BigEndian.PutUint32(b[4:], p.flags)
+	}
+	binary.BigEndian.PutUint32(b[8:], p.timestamp)
+	binary.BigEndian.PutUint32(b[12:], p.socketID)
+	copy(b[headerSize:], p.payload)
+	return b
+}
+
+func (p *packet) Unmarshal(b []byte) error {
+	if len(b) < headerSize {
+		return errors.New("srt: packet too short")
+	}
+	if p.control = b[0]&0x80 != 0; p.control {
+		p.ctrlType = binary.BigEndian.Uint16(b) & 0x7FFF
+		p.subtype = binary.BigEndian.Uint16(b[2:])
+		p.info = binary.BigEndian.Uint32(b[4:])
+	} else {
+		p.seq = binary.BigEndian.Uint32(b)
+		p.flags = binary.BigEndian.Uint32(b[4:])
+	}
+	p.timestamp = binary.BigEndian.Uint32(b[8:])
+	p.socketID = binary.BigEndian.Uint32(b[12:])
+	p.payload = b[headerSize:]
+	return nil
+}
+
+// seqDiff - signed distance between 31-bit sequence numbers
+func seqDiff(a, b uint32) int32 {
+	return int32((a-b)<<1) >> 1
+}
+
+func seqNext(seq uint32) uint32 {
+	return (seq + 1) & seqMask
+}
+
+// marshalLossList - NAK ranges, first number of the range with the high bit
+func marshalLossList(seqs []uint32) []byte {
+	var b []byte
+	for i := 0; i < len(seqs); {
+		j := i
+		for j+1 < len(seqs) && seqs[j+1] == seqNext(seqs[j]) {
+			j++
+		}
+		if i == j {
+			b = binary.BigEndian.AppendUint32(b, seqs[i])
+		} else {
+			b = binary.BigEndian.AppendUint32(b, seqs[i]|0x80000000)
+			b = binary.BigEndian.AppendUint32(b, seqs[j])
+		}
+		i = j + 1
+	}
+	return b
+}
+
+func unmarshalLossList(b []byte) (seqs []uint32) {
+	for ; len(b) >= 4; b = b[4:] {
+		seq := binary.BigEndian.Uint32(b)
+		if seq&0x80000000 == 0 || len(b) < 8 {
+			seqs = append(seqs, seq&seqMask)
+			continue
+		}
+
+		b = b[4:]
+		last := binary.BigEndian.Uint32(b) & seqMask
+		seq &= seqMask
+
+		// protection from the huge ranges
+		for n := 0; n < 8192; n++ {
+			seqs = append(seqs, seq)
+			if seq == last {
+				break
+			}
+			seq = seqNext(seq)
+		}
+	}
+	return
+}
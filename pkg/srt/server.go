@@ -0,0 +1,269 @@
+package srt
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Listener - SRT server in the listener mode, all connections use one UDP socket
+type Listener struct {
+	Latency    time.Duration
+	Passphrase string
+
+	// Authorize - optional check of the stream ID before the connection is accepted,
+	// return zero for the accepted connection or one of the Reject codes
+	Authorize func(streamID string, addr net.Addr) uint32
+
+	conn   *net.UDPConn
+	conns  map[uint32]*Conn
+	mu     sync.Mutex
+	accept chan *Conn
+	secret []byte
+	done   chan struct{}
+}
+
+func Listen(address string) (*Listener, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetReadBuffer(1 << 20)
+
+	l := &Listener{
+		Latency: DefaultLatency,
+		conn:    conn,
+		conns:   map[uint32]*Conn{},
+		accept:  make(chan *Conn),
+		secret:  make([]byte, 32),
+		done:    make(chan struct{}),
+	}
+	_, _ = rand.Read(l.secret)
+
+	go l.serve()
+
+	return l, nil
+}
+
+func (l *Listener) Accept() (*Conn, error) {
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *Listener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+func (l *Listener) Close() error {
+	return l.conn.Close()
+}
+
+func (l *Listener) serve() {
+	defer close(l.done)
+
+	b := make([]byte, defaultMTU)
+	pkt := &packet{}
+
+	for {
+		n, addr, err := l.conn.ReadFromUDP(b)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				break
+			}
+			continue
+		}
+
+		if err = pkt.Unmarshal(b[:n]); err != nil {
+			continue
+		}
+
+		if pkt.socketID != 0 {
+			l.mu.Lock()
+			c := l.conns[pkt.socketID]
+			l.mu.Unlock()
+
+			if c != nil && c.remote.String() == addr.String() {
+				c.handle(b[:n])
+			}
+			continue
+		}
+
+		if pkt.control && pkt.ctrlType == ctrlHandshake {
+			l.handleHandshake(pkt, addr)
+		}
+	}
+
+	l.mu.Lock()
+	for _, c := range l.conns {
+		c.close(net.ErrClosed)
+	}
+	l.mu.Unlock()
+}
+
+func (l *Listener) handleHandshake(pkt *packet, addr *net.UDPAddr) {
+	req := &handshake{}
+	if err := req.Unmarshal(pkt.payload); err != nil {
+		return
+	}
+
+	switch req.hsType {
+	case hsTypeInduction:
+		res := &handshake{
+			version:    5,
+			extension:  hsMagicCode,
+			initialSeq: req.initialSeq,
+			mtu:        defaultMTU,
+			flowWindow: defaultFlowWindow,
+			hsType:     hsTypeInduction,
+			cookie:     l.cookie(addr, time.Now()),
+		}
+		l.sendHandshake(res, req.socketID, addr)
+
+	case hsTypeConclusion:
+		now := time.Now()
+		if req.cookie != l.cookie(addr, now) && req.cookie != l.cookie(addr, now.Add(-time.Minute)) {
+			return
+		}
+
+		// repeat response for the retransmitted conclusion request
+		if res := l.findResponse(req.socketID, addr); res != nil {
+			_, _ = l.conn.WriteToUDP(res, addr)
+			return
+		}
+
+		c, res, reason := l.conclusion(req, addr)
+		if reason != 0 {
+			req.hsType = reason
+			req.km = nil
+			req.srtVersion = 0
+			l.sendHandshake(req, req.socketID, addr)
+			return
+		}
+
+		b := l.sendHandshake(res, req.socketID, addr)
+
+		c.onClose = func() {
+			l.mu.Lock()
+			delete(l.conns, c.localID)
+			l.mu.Unlock()
+		}
+		c.write = func(b []byte) error {
+			_, err := l.conn.WriteToUDP(b, addr)
+			return err
+		}
+		c.response = b
+
+		l.mu.Lock()
+		l.conns[c.localID] = c
+		l.mu.Unlock()
+
+		go c.runTimers()
+
+		go func() {
+			select {
+			case l.accept <- c:
+			case <-l.done:
+			case <-time.After(handshakeTimeout):
+				_ = c.Close() // nobody accepts the connection
+			}
+		}()
+	}
+}
+
+func (l *Listener) conclusion(req *handshake, addr *net.UDPAddr) (*Conn, *handshake, uint32) {
+	if req.version != 5 {
+		return nil, nil, RejectUnknown + 8 // SRT_REJ_VERSION
+	}
+
+	var km *keyMaterial
+
+	switch {
+	case l.Passphrase != "" && req.km != nil:
+		var err error
+		if km, err = parseKeyMaterial(req.km, l.Passphrase); err != nil {
+			return nil, nil, RejectBadSecret
+		}
+	case l.Passphrase != "" || req.km != nil:
+		return nil, nil, RejectUnsecure
+	}
+
+	if l.Authorize != nil {
+		if reason := l.Authorize(req.streamID, addr); reason != 0 {
+			return nil, nil, reason
+		}
+	}
+
+	latency := max(l.Latency, time.Duration(req.recvDelay)*time.Millisecond, time.Duration(req.sendDelay)*time.Millisecond)
+
+	// listener uses caller initial sequence number for both directions
+	c := newConn(randSocketID(), req.socketID, req.initialSeq, addr, latency, km)
+	c.StreamID = req.streamID
+	if km != nil {
+		c.pass = l.Passphrase
+	}
+
+	res := &handshake{
+		version:    5,
+		extension:  hsExtHSREQ,
+		initialSeq: req.initialSeq,
+		mtu:        defaultMTU,
+		flowWindow: defaultFlowWindow,
+		hsType:     hsTypeConclusion,
+		socketID:   c.localID,
+		cookie:     req.cookie,
+		srtVersion: srtVersion,
+		srtFlags:   srtFlags,
+		recvDelay:  uint16(latency.Milliseconds()),
+		sendDelay:  uint16(latency.Milliseconds()),
+		response:   true,
+	}
+
+	if km != nil {
+		res.extension |= hsExtKMREQ
+		res.km = req.km // same keys for both directions
+	}
+
+	return c, res, 0
+}
+
+func (l *Listener) findResponse(peerID uint32, addr *net.UDPAddr) []byte {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, c := range l.conns {
+		if c.peerID == peerID && c.remote.String() == addr.String() {
+			return c.response
+		}
+	}
+	return nil
+}
+
+func (l *Listener) sendHandshake(hs *handshake, socketID uint32, addr *net.UDPAddr) []byte {
+	pkt := &packet{control: true, ctrlType: ctrlHandshake, socketID: socketID, payload: hs.Marshal()}
+	b := pkt.Marshal()
+	_, _ = l.conn.WriteToUDP(b, addr)
+	return b
+}
+
+// cookie - SYN cookie for the caller address, valid for current and previous minute
+func (l *Listener) cookie(addr *net.UDPAddr, now time.Time) uint32 {
+	h := hmac.New(sha256.New, l.secret)
+	h.Write([]byte(addr.String() + "\n" + strconv.FormatInt(now.Unix()/60, 10)))
+	return binary.BigEndian.Uint32(h.Sum(nil))
+}
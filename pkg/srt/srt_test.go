@@ -0,0 +1,117 @@
+package srt
+
+import (
+	"encoding/hex"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyWrap(t *testing.T) {
+	// RFC 3394, 4.1 Wrap 128 bits of Key Data with a 128-bit KEK
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF")
+
+	wrapped, err := keyWrap(kek, key)
+	require.Nil(t, err)
+	require.Equal(t, "1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5", hex.EncodeToString(wrapped))
+
+	unwrapped, err := keyUnwrap(kek, wrapped)
+	require.Nil(t, err)
+	require.Equal(t, key, unwrapped)
+
+	kek[0] = 0xFF
+	_, err = keyUnwrap(kek, wrapped)
+	require.Equal(t, errBadSecret, err)
+}
+
+func TestParseKeyMaterial(t *testing.T) {
+	km, err := newKeyMaterial(24)
+	require.Nil(t, err)
+
+	b, err := km.Marshal("secret123456")
+	require.Nil(t, err)
+
+	km2, err := parseKeyMaterial(b, "secret123456")
+	require.Nil(t, err)
+	require.Equal(t, km.keys[0], km2.keys[0])
+
+	// short salt and wrong key length from the peer
+	for _, i := range []int{14, 15} {
+		bad := append([]byte(nil), b...)
+		bad[i] = 1
+		_, err = parseKeyMaterial(bad, "secret123456")
+		require.NotNil(t, err)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	hs := &handshake{
+		version:    5,
+		extension:  hsExtHSREQ | hsExtConfig,
+		hsType:     hsTypeConclusion,
+		srtVersion: srtVersion,
+		srtFlags:   srtFlags,
+		recvDelay:  120,
+		sendDelay:  120,
+		streamID:   "#!::r=camera1,m=publish",
+	}
+
+	hs2 := &handshake{}
+	require.Nil(t, hs2.Unmarshal(hs.Marshal()))
+	require.Equal(t, hs, hs2)
+}
+
+func TestLossList(t *testing.T) {
+	seqs := []uint32{1, 3, 4, 5, 7, seqMask, 0}
+	require.Equal(t, seqs, unmarshalLossList(marshalLossList(seqs)))
+}
+
+func TestDial(t *testing.T) {
+	ln, err := Listen("127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+
+	ln.Passphrase = "secret123456"
+	ln.Authorize = func(streamID string, addr net.Addr) uint32 {
+		if streamID != "camera1" {
+			return RejectNotFound
+		}
+		return 0
+	}
+
+	addr := "srt://" + ln.Addr().String()
+
+	_, err = Dial(addr + "?streamid=camera2&passphrase=secret123456")
+	require.Equal(t, RejectError(RejectNotFound), err)
+
+	_, err = Dial(addr + "?streamid=camera1&passphrase=wrong123456")
+	require.Equal(t, RejectError(RejectBadSecret), err)
+
+	caller, err := Dial(addr + "?streamid=camera1&passphrase=secret123456&pbkeylen=32")
+	require.Nil(t, err)
+
+	conn, err := ln.Accept()
+	require.Nil(t, err)
+	require.Equal(t, "camera1", conn.StreamID)
+
+	data := make([]byte, 5*PayloadSize)
+	for i := range data {
+		data[i] = byte(i)
+	}
+
+	_, err = caller.Write(data)
+	require.Nil(t, err)
+
+	b := make([]byte, len(data))
+	_, err = io.ReadFull(conn, b)
+	require.Nil(t, err)
+	require.Equal(t, data, b)
+
+	require.Nil(t, caller.Close())
+
+	_, err = conn.Read(b)
+	require.NotNil(t, err)
+}
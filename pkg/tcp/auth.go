@@ -15,6 +15,8 @@ type Auth struct {
 	pass    string
 	header  string
 	h1nonce string
+
+	validate func(user, pass string) bool
 }
 
 const (
@@ -35,6 +37,11 @@ func NewAuth(user *url.Userinfo) *Auth {
 	return a
 }
 
+// NewAuthFunc - server side auth with custom credentials validation (only Basic)
+func NewAuthFunc(validate func(user, pass string) bool) *Auth {
+	return &Auth{Method: AuthBasic, validate: validate}
+}
+
 func (a *Auth) Read(res *Response) bool {
 	auth := res.Header.Get("WWW-Authenticate")
 	if len(auth) < 6 {
@@ -95,6 +102,15 @@ func (a *Auth) Validate(req *Request) (valid, empty bool) {
 		return false, true
 	}
 
+	if a.validate != nil {
+		user, pass, ok := parseBasic(header)
+		if !ok || !a.validate(user, pass) {
+			return false, false
+		}
+		a.user = user
+		return true, false
+	}
+
 	if a.Method == AuthUnknown {
 		a.Method = AuthBasic
 		a.header = "Basic " + B64(a.user, a.pass)
@@ -116,6 +132,27 @@ func (a *Auth) UserInfo() *url.Userinfo {
 	return url.UserPassword(a.user, a.pass)
 }
 
+// Username return the username from the auth config or from the validated request
+func (a *Auth) Username() string {
+	if a == nil {
+		return ""
+	}
+	return a.user
+}
+
+func parseBasic(header string) (user, pass string, ok bool) {
+	b64, ok := strings.CutPrefix(header, "Basic ")
+	if !ok {
+		return
+	}
+	b, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return "", "", false
+	}
+	user, pass, ok = strings.Cut(string(b), ":")
+	return
+}
+
 func Between(s, sub1, sub2 string) string {
 	i := strings.Index(s, sub1)
 	if i < 0 {
@@ -0,0 +1,16 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"strings"
+)
+
+// LoadCertificate - for TLS servers, cert and key can be file paths or PEM content
+func LoadCertificate(cert, key string) (tls.Certificate, error) {
+	if strings.IndexByte(cert, '\n') < 0 && strings.IndexByte(key, '\n') < 0 {
+		// check if file path
+		return tls.LoadX509KeyPair(cert, key)
+	}
+	// if text file content
+	return tls.X509KeyPair([]byte(cert), []byte(key))
+}
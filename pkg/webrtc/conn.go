@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/AlexxIT/go2rtc/pkg/core"
@@ -22,6 +23,8 @@ type Conn struct {
 
 	offer  string
 	closed core.Waiter
+
+	sendersMu sync.Mutex // active consumer can get new tracks after connection
 }
 
 func NewConn(pc *webrtc.PeerConnection) *Conn {
@@ -34,12 +37,7 @@ func NewConn(pc *webrtc.PeerConnection) *Conn {
 		pc: pc,
 	}
 
-	pc.OnICECandidate(func(candidate *webrtc.ICECandidate) {
-		// last candidate will be empty
-		if candidate != nil {
-			c.Fire(candidate)
-		}
-	})
+	pc.OnICECandidate(c.onICECandidate)
 
 	pc.OnDataChannel(func(channel *webrtc.DataChannel) {
 		c.Fire(channel)
@@ -134,9 +132,11 @@ func NewConn(pc *webrtc.PeerConnection) *Conn {
 
 		switch state {
 		case webrtc.PeerConnectionStateConnected:
+			c.sendersMu.Lock()
 			for _, sender := range c.Senders {
 				sender.Start()
 			}
+			c.sendersMu.Unlock()
 		case webrtc.PeerConnectionStateDisconnected, webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateClosed:
 			// disconnect event comes earlier, than failed
 			// but it comes only for success connections
@@ -147,6 +147,13 @@ func NewConn(pc *webrtc.PeerConnection) *Conn {
 	return c
 }
 
+func (c *Conn) onICECandidate(candidate *webrtc.ICECandidate) {
+	// last candidate will be empty
+	if candidate != nil {
+		c.Fire(candidate)
+	}
+}
+
 func (c *Conn) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.Connection)
 }
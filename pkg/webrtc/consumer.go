@@ -28,6 +28,7 @@ func (c *Conn) AddTrack(media *core.Media, codec *core.Codec, track *core.Receiv
 	case core.ModePassiveConsumer: // video/audio for browser
 	case core.ModeActiveProducer: // go2rtc as WebRTC client (backchannel)
 	case core.ModePassiveProducer: // WebRTC/WHIP
+	case core.ModeActiveConsumer: // go2rtc as WHIP client
 	default:
 		panic(core.Caller())
 	}
@@ -78,13 +79,15 @@ func (c *Conn) AddTrack(media *core.Media, codec *core.Codec, track *core.Receiv
 	}
 
 	// TODO: rewrite this dirty logic
-	// maybe not best solution, but ActiveProducer connected before AddTrack
-	if c.Mode != core.ModeActiveProducer {
+	// maybe not best solution, but ActiveProducer and ActiveConsumer connected before AddTrack
+	if c.Mode != core.ModeActiveProducer && c.Mode != core.ModeActiveConsumer {
 		sender.Bind(track)
 	} else {
 		sender.HandleRTP(track)
 	}
 
+	c.sendersMu.Lock()
 	c.Senders = append(c.Senders, sender)
+	c.sendersMu.Unlock()
 	return nil
 }
@@ -0,0 +1,204 @@
+package webrtc
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v4"
+)
+
+const MimeTrickleICE = "application/trickle-ice-sdpfrag"
+
+// GatheringTimeout - max wait time for local candidates on ICE restart
+var GatheringTimeout = 5 * time.Second
+
+// SDPFrag - SDP fragment for the trickle ICE and ICE restart (RFC 8840)
+// from WHIP/WHEP PATCH requests
+type SDPFrag struct {
+	Ufrag      string
+	Pwd        string
+	Media      string // first media line for the response, ex. "audio 9 UDP/TLS/RTP/SAVPF 0"
+	Mid        string
+	Candidates []string // ex. "candidate:1 1 udp 2130706431 192.168.1.123 8555 typ host"
+}
+
+func ParseSDPFrag(s string) *SDPFrag {
+	frag := &SDPFrag{}
+
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "a=ice-ufrag:"):
+			frag.Ufrag = line[len("a=ice-ufrag:"):]
+		case strings.HasPrefix(line, "a=ice-pwd:"):
+			frag.Pwd = line[len("a=ice-pwd:"):]
+		case strings.HasPrefix(line, "m="):
+			if frag.Media == "" {
+				frag.Media = line[2:]
+			}
+		case strings.HasPrefix(line, "a=mid:"):
+			if frag.Mid == "" {
+				frag.Mid = line[len("a=mid:"):]
+			}
+		case strings.HasPrefix(line, "a=candidate:"):
+			frag.Candidates = append(frag.Candidates, line[2:])
+		}
+	}
+
+	return frag
+}
+
+func (f *SDPFrag) String() string {
+	s := "a=ice-ufrag:" + f.Ufrag + "\r\na=ice-pwd:" + f.Pwd + "\r\n"
+	if f.Media != "" {
+		s += "m=" + f.Media + "\r\na=mid:" + f.Mid + "\r\n"
+	}
+	for _, candidate := range f.Candidates {
+		s += "a=" + candidate + "\r\n"
+	}
+	return s + "a=end-of-candidates\r\n"
+}
+
+// IsICERestart - remote peer sends new ICE credentials
+func (c *Conn) IsICERestart(frag *SDPFrag) bool {
+	if frag.Ufrag == "" {
+		return false
+	}
+	ufrag, _ := iceCredentials(c.pc.RemoteDescription())
+	return frag.Ufrag != ufrag
+}
+
+// LocalUfrag - current local ICE username fragment
+func (c *Conn) LocalUfrag() string {
+	ufrag, _ := iceCredentials(c.pc.LocalDescription())
+	return ufrag
+}
+
+// RestartICE - process ICE restart from the remote offerer (WHIP/WHEP client)
+// and return new local ICE credentials with candidates
+func (c *Conn) RestartICE(frag *SDPFrag, candidates []string, filter func(*webrtc.ICECandidate) bool) (*SDPFrag, error) {
+	remote := c.pc.RemoteDescription()
+	if remote == nil || remote.Type != webrtc.SDPTypeOffer {
+		return nil, errors.New("webrtc: ICE restart only for remote offer")
+	}
+
+	var done = make(chan struct{}, 1)
+	var mu sync.Mutex
+
+	c.pc.OnICECandidate(func(candidate *webrtc.ICECandidate) {
+		// keep trickle events for the connection listeners
+		c.onICECandidate(candidate)
+
+		if candidate == nil {
+			select {
+			case done <- struct{}{}:
+			default:
+			}
+			return
+		}
+
+		if filter == nil || filter(candidate) {
+			mu.Lock()
+			candidates = append(candidates, candidate.ToJSON().Candidate)
+			mu.Unlock()
+		}
+	})
+	defer c.pc.OnICECandidate(c.onICECandidate)
+
+	// same remote offer with new ICE credentials
+	sd := &sdp.SessionDescription{}
+	if err := sd.Unmarshal([]byte(remote.SDP)); err != nil {
+		return nil, err
+	}
+
+	setICECredentials(sd, frag.Ufrag, frag.Pwd)
+
+	b, err := sd.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	desc := webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: string(b)}
+	if err = c.pc.SetRemoteDescription(desc); err != nil {
+		return nil, err
+	}
+
+	if desc, err = c.pc.CreateAnswer(nil); err != nil {
+		return nil, err
+	}
+	if err = c.pc.SetLocalDescription(desc); err != nil {
+		return nil, err
+	}
+
+	// answer with already gathered candidates if gathering takes too long
+	select {
+	case <-done:
+	case <-time.After(GatheringTimeout):
+	}
+
+	for _, candidate := range frag.Candidates {
+		_ = c.AddCandidate(candidate)
+	}
+
+	mu.Lock()
+	local := &SDPFrag{Mid: frag.Mid, Candidates: slices.Clone(candidates)}
+	mu.Unlock()
+	local.Ufrag, local.Pwd = iceCredentials(c.pc.LocalDescription())
+
+	if sd, err = c.pc.LocalDescription().Unmarshal(); err == nil && len(sd.MediaDescriptions) > 0 {
+		md := sd.MediaDescriptions[0]
+		local.Media = md.MediaName.String()
+		local.Mid, _ = md.Attribute("mid")
+	}
+
+	return local, nil
+}
+
+func iceCredentials(desc *webrtc.SessionDescription) (ufrag, pwd string) {
+	if desc == nil {
+		return
+	}
+
+	sd, err := desc.Unmarshal()
+	if err != nil {
+		return
+	}
+
+	// credentials can be on the session or the media level
+	ufrag, _ = sd.Attribute("ice-ufrag")
+	pwd, _ = sd.Attribute("ice-pwd")
+
+	for _, md := range sd.MediaDescriptions {
+		if ufrag == "" {
+			ufrag, _ = md.Attribute("ice-ufrag")
+		}
+		if pwd == "" {
+			pwd, _ = md.Attribute("ice-pwd")
+		}
+	}
+
+	return
+}
+
+func setICECredentials(sd *sdp.SessionDescription, ufrag, pwd string) {
+	replace := func(attrs []sdp.Attribute) {
+		for i, attr := range attrs {
+			switch attr.Key {
+			case "ice-ufrag":
+				attrs[i].Value = ufrag
+			case "ice-pwd":
+				attrs[i].Value = pwd
+			}
+		}
+	}
+
+	replace(sd.Attributes)
+	for _, md := range sd.MediaDescriptions {
+		replace(md.Attributes)
+	}
+}
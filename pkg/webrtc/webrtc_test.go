@@ -69,3 +69,26 @@ a=fingerprint:sha-256 A2:93:53:50:E4:2F:C5:4E:DF:7C:70:99:5A:A7:39:50:1A:63:E5:B
 	_, err = conn.GetAnswer()
 	require.Nil(t, err)
 }
+
+func TestSDPFrag(t *testing.T) {
+	// from RFC 9725 (WHIP)
+	s := "a=ice-options:trickle ice2\r\n" +
+		"a=group:BUNDLE 0 1\r\n" +
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+		"a=mid:0\r\n" +
+		"a=ice-ufrag:EsAw\r\n" +
+		"a=ice-pwd:bP+XJMM09aR8AiX1jdukzR6Y\r\n" +
+		"a=candidate:1387637174 1 udp 2122260223 192.0.2.1 61764 typ host generation 0 ufrag EsAw network-id 1\r\n" +
+		"a=candidate:3471623853 1 udp 2122194687 198.51.100.2 61765 typ host generation 0 ufrag EsAw network-id 2\r\n" +
+		"a=end-of-candidates\r\n"
+
+	frag := ParseSDPFrag(s)
+	require.Equal(t, "EsAw", frag.Ufrag)
+	require.Equal(t, "bP+XJMM09aR8AiX1jdukzR6Y", frag.Pwd)
+	require.Equal(t, "audio 9 UDP/TLS/RTP/SAVPF 111", frag.Media)
+	require.Equal(t, "0", frag.Mid)
+	require.Len(t, frag.Candidates, 2)
+	require.Equal(t, "candidate:1387637174 1 udp 2122260223 192.0.2.1 61764 typ host generation 0 ufrag EsAw network-id 1", frag.Candidates[0])
+
+	require.Equal(t, frag, ParseSDPFrag(frag.String()))
+}